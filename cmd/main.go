@@ -1,60 +1,216 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"e-commerce_marketplace/internal/config"
 	"e-commerce_marketplace/internal/handlers"
+	"e-commerce_marketplace/internal/metrics"
+	"e-commerce_marketplace/internal/middleware"
+	"e-commerce_marketplace/internal/models"
 	"e-commerce_marketplace/internal/repositories"
 	"e-commerce_marketplace/internal/routes"
 	"e-commerce_marketplace/internal/services"
+	"e-commerce_marketplace/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/recover"
-	"github.com/joho/godotenv"
 )
 
 func main() {
-	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found")
+	// Load configuration from .env, CONFIG_FILE and the environment
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// e.g. `go run ./cmd migrate status`
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(cfg, os.Args[2:])
+		return
 	}
 
 	// Initialize database
-	db, err := config.InitDatabase()
+	db, err := config.InitDatabase(cfg.Database)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
+	// Expose connection pool statistics
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatal("Failed to access connection pool:", err)
+	}
+	if err := metrics.RegisterDBStats(sqlDB); err != nil {
+		log.Fatal("Failed to register database metrics:", err)
+	}
+
 	// Initialize repositories
 	walletRepo := repositories.NewWalletRepository(db)
+	transactionRepo := repositories.NewTransactionRepository(db)
+	holdRepo := repositories.NewHoldRepository(db)
+	batchRepo := repositories.NewBatchRepository(db)
+	apiClientRepo := repositories.NewAPIClientRepository(db)
+	webhookRepo := repositories.NewWebhookRepository(db)
+	outboxRepo := repositories.NewOutboxRepository(db)
+	txManager := repositories.NewTxManager(db)
+
+	// Background workers run until shutdown
+	workers := newWorkerGroup()
+
+	// Balance types come from the configured registry through an in-memory cache
+	var balanceTypeRegistry services.BalanceTypeRegistry
+	var frappeRegistry *services.FrappeBalanceTypeRegistry
+	switch cfg.BalanceTypes.Backend {
+	case services.BalanceTypeBackendFrappe:
+		frappeClient := utils.NewFrappeClient(cfg.Frappe.URL, cfg.Frappe.APIKey, cfg.Frappe.APISecret, cfg.Frappe.Timeout)
+		frappeRegistry = services.NewFrappeBalanceTypeRegistry(frappeClient, cfg.BalanceTypes.Scales)
+		balanceTypeRegistry = frappeRegistry
+	case services.BalanceTypeBackendLocal:
+		balanceTypeRegistry = services.NewLocalBalanceTypeRegistry(repositories.NewBalanceTypeRepository(db))
+	}
+	balanceTypeConfig := services.DefaultBalanceTypeProviderConfig()
+
+	// With a Frappe webhook secret, Frappe pushes balance type changes and
+	// polling only remains as a safety net
+	frappePush := cfg.Frappe.WebhookSecret != "" && cfg.BalanceTypes.Backend == services.BalanceTypeBackendFrappe
+	if frappePush {
+		balanceTypeConfig.TTL = time.Hour
+		balanceTypeConfig.RefreshInterval = 15 * time.Minute
+	}
+	if cfg.BalanceTypes.CacheTTL > 0 {
+		balanceTypeConfig.TTL = cfg.BalanceTypes.CacheTTL
+	}
+	balanceTypeProvider := services.NewBalanceTypeProvider(balanceTypeRegistry, balanceTypeConfig)
+	workers.Go(balanceTypeProvider.Run)
+
+	// JWT verification is enabled by pointing JWT_JWKS_FILE (service tokens)
+	// and USER_JWT_JWKS_FILE (end-user tokens) at local JWKS files
+	jwtConfig := loadJWTConfig("JWT", cfg.Auth.JWT)
+	userJWTConfig := loadJWTConfig("USER_JWT", cfg.Auth.UserJWT)
 
 	// Initialize services
-	walletService := services.NewWalletService(walletRepo)
+	webhookService := services.NewWebhookService(webhookRepo)
+	walletService := services.NewWalletService(walletRepo, transactionRepo, holdRepo, txManager, balanceTypeProvider, services.NewOutboxPublisher(outboxRepo), cfg.Holds.TTL)
+	balanceTypeService := services.NewBalanceTypeService(balanceTypeRegistry, balanceTypeProvider)
+	batchService := services.NewBatchService(batchRepo, walletService, services.DefaultBatchChunkSize)
+	apiClientService := services.NewAPIClientService(apiClientRepo)
+	authService := services.NewAuthService(apiClientRepo, jwtConfig, userJWTConfig)
+	healthService := services.NewHealthService(repositories.NewHealthRepository(db), balanceTypeRegistry, balanceTypeProvider, cfg.Server.HealthCheckTimeout)
+
+	// Bootstrap the first admin client, e.g. for a fresh deployment
+	if cfg.Auth.BootstrapAPIKey != "" {
+		if err := apiClientService.EnsureClient("bootstrap", cfg.Auth.BootstrapAPIKey, cfg.Auth.BootstrapAPISecret, models.AllScopes); err != nil {
+			log.Fatal("Failed to bootstrap API client:", err)
+		}
+	}
+
+	// Drain the outbox to the configured sinks, e.g. EVENT_SINKS=webhook,nats
+	eventSinks := loadEventSinks(cfg.Events, webhookService)
+	workers.Go(services.NewOutboxDispatcher(outboxRepo, eventSinks, services.DefaultOutboxDispatcherConfig()).Run)
+
+	// Deliver webhooks in the background
+	workers.Go(services.NewWebhookDispatcher(webhookRepo, services.DefaultWebhookDispatcherConfig()).Run)
+
+	// Release expired holds in the background
+	workers.Go(services.NewHoldExpirer(walletService, time.Minute).Run)
 
 	// Initialize handlers
 	walletHandler := handlers.NewWalletHandler(walletService)
+	balanceTypeHandler := handlers.NewBalanceTypeHandler(balanceTypeService)
+	batchHandler := handlers.NewBatchHandler(batchService)
+	apiClientHandler := handlers.NewAPIClientHandler(apiClientService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	healthHandler := handlers.NewHealthHandler(healthService)
 
 	// Initialize Fiber app
-	app := fiber.New(fiber.Config{})
+	app := fiber.New(fiber.Config{
+		// batch CSV uploads can hold up to 100,000 rows
+		BodyLimit: 16 * 1024 * 1024,
+	})
 
 	// Middleware
 	app.Use(recover.New())
+	app.Use(middleware.Metrics())
 	app.Use(cors.New())
+	app.Use("/api/v1", middleware.Authenticate(authService))
 
 	// Routes
-	routes.WalletRoutes(app, walletHandler)
+	routes.HealthRoutes(app, healthHandler)
+	routes.MetricsRoutes(app)
+	routes.WalletRoutes(app, walletHandler, walletService)
+	routes.BalanceTypeRoutes(app, balanceTypeHandler)
+	routes.BatchRoutes(app, batchHandler)
+	routes.APIClientRoutes(app, apiClientHandler)
+	routes.WebhookRoutes(app, webhookHandler)
+	if frappePush {
+		balanceTypeSyncService := services.NewBalanceTypeSyncService(frappeRegistry, balanceTypeProvider, walletRepo, cfg.BalanceTypes.Backfill)
+		workers.Go(balanceTypeSyncService.Run)
+		routes.FrappeRoutes(app, handlers.NewFrappeHandler(balanceTypeSyncService, cfg.Frappe.WebhookSecret))
+	}
+
+	// Stop gracefully on SIGINT/SIGTERM; a second signal exits immediately
+	signals, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
 
 	// Start server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("Server starting on port %s", cfg.Server.Port)
+		serverErr <- app.Listen(":" + cfg.Server.Port)
+	}()
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			log.Fatal("Failed to start server:", err)
+		}
+	case <-signals.Done():
+	}
+	stopSignals()
+
+	log.Printf("Shutting down, waiting up to %s", cfg.Server.ShutdownTimeout)
+	shutdown(app, healthService, batchService, workers, eventSinks, db, cfg.Server.ShutdownDelay, cfg.Server.ShutdownTimeout)
+}
+
+// loadJWTConfig loads the keys of one kind of JWT; prefix names its settings in errors
+func loadJWTConfig(prefix string, cfg config.JWTConfig) services.JWTConfig {
+	jwtConfig := services.JWTConfig{
+		Issuer:   cfg.Issuer,
+		Audience: cfg.Audience,
+	}
+	if cfg.JWKSFile != "" {
+		keys, err := utils.LoadJWKS(cfg.JWKSFile)
+		if err != nil {
+			log.Fatalf("Failed to load %s_JWKS_FILE: %v", prefix, err)
+		}
+		jwtConfig.Keys = keys
 	}
+	return jwtConfig
+}
 
-	log.Printf("Server starting on port %s", port)
-	if err := app.Listen(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
+// loadEventSinks builds the configured event sinks
+func loadEventSinks(cfg config.EventConfig, webhookService services.WebhookService) []services.EventSink {
+	var sinks []services.EventSink
+	for _, name := range cfg.Sinks {
+		switch name {
+		case services.EventSinkWebhook:
+			sinks = append(sinks, webhookService)
+		case services.EventSinkStdout:
+			sinks = append(sinks, services.NewStdoutSink(os.Stdout))
+		case services.EventSinkNATS:
+			sink, err := services.NewNATSSink(cfg.NATSURL, cfg.NATSSubjectPrefix)
+			if err != nil {
+				log.Fatal("Failed to connect to NATS:", err)
+			}
+			sinks = append(sinks, sink)
+		}
 	}
-}
\ No newline at end of file
+	return sinks
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"e-commerce_marketplace/internal/config"
+	"e-commerce_marketplace/internal/migrations"
+)
+
+const migrateUsage = `Usage: migrate <command>
+
+Commands:
+  up          apply all pending migrations
+  down [n]    roll back the last n applied migrations (default 1)
+  status      list migrations and when they were applied`
+
+// runMigrate handles the "migrate" subcommand
+func runMigrate(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	db, err := config.OpenDatabase(cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	migrator, err := migrations.New(db)
+	if err != nil {
+		log.Fatal("Failed to load migrations:", err)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		for _, migration := range applied {
+			fmt.Printf("applied   %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatal("Migration failed:", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				log.Fatalf("Invalid number of migrations to roll back: %q", args[1])
+			}
+		}
+		rolledBack, err := migrator.Down(steps)
+		for _, migration := range rolledBack {
+			fmt.Printf("rolled back %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatal("Rollback failed:", err)
+		}
+		if len(rolledBack) == 0 {
+			fmt.Println("no applied migrations")
+		}
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			log.Fatal("Failed to read migration status:", err)
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%04d_%-40s %s\n", status.Version, status.Name, appliedAt)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"e-commerce_marketplace/internal/config"
+	"e-commerce_marketplace/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// workerGroup runs background workers until they are stopped
+type workerGroup struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newWorkerGroup() *workerGroup {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &workerGroup{ctx: ctx, cancel: cancel}
+}
+
+// Go starts run in the background; its ctx is cancelled by Stop
+func (g *workerGroup) Go(run func(ctx context.Context)) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		run(g.ctx)
+	}()
+}
+
+// Stop cancels the workers and waits until they return or ctx is done
+func (g *workerGroup) Stop(ctx context.Context) error {
+	g.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shutdown stops the service: readiness turns false for delay, then within
+// timeout the server stops accepting requests and drains in-flight ones,
+// batches and background workers stop, and finally the event sinks and the
+// database pool are closed
+func shutdown(app *fiber.App, healthService services.HealthService, batchService services.BatchService, workers *workerGroup, eventSinks []services.EventSink, db *gorm.DB, delay, timeout time.Duration) {
+	healthService.SetShuttingDown()
+	time.Sleep(delay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := app.ShutdownWithContext(ctx); err != nil {
+		log.Printf("Failed to drain in-flight requests: %v", err)
+	}
+	if err := batchService.Shutdown(ctx); err != nil {
+		log.Printf("Failed to stop running batches: %v", err)
+	}
+	if err := workers.Stop(ctx); err != nil {
+		log.Printf("Failed to stop background workers: %v", err)
+	}
+	for _, sink := range eventSinks {
+		if closer, ok := sink.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				log.Printf("Failed to close %s event sink: %v", sink.Name(), err)
+			}
+		}
+	}
+	if err := config.CloseDatabase(db); err != nil {
+		log.Printf("Failed to close database: %v", err)
+	}
+	log.Println("Server stopped")
+}
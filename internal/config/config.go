@@ -0,0 +1,428 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds all service settings. Load fills it from defaults, an optional
+// YAML file and the environment, in that order of precedence.
+type Config struct {
+	Server       ServerConfig      `yaml:"server"`
+	Database     DatabaseConfig    `yaml:"database"`
+	Frappe       FrappeConfig      `yaml:"frappe"`
+	BalanceTypes BalanceTypeConfig `yaml:"balance_types"`
+	Holds        HoldConfig        `yaml:"holds"`
+	Auth         AuthConfig        `yaml:"auth"`
+	Events       EventConfig       `yaml:"events"`
+}
+
+// ServerConfig configures the HTTP server
+type ServerConfig struct {
+	Port string `yaml:"port"`
+
+	// ShutdownTimeout bounds draining requests and stopping workers on SIGTERM
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	// ShutdownDelay keeps serving while /readyz reports not ready, so load
+	// balancers stop sending traffic before the server stops accepting it
+	ShutdownDelay time.Duration `yaml:"shutdown_delay"`
+
+	// HealthCheckTimeout bounds each dependency check of /readyz
+	HealthCheckTimeout time.Duration `yaml:"health_check_timeout"`
+}
+
+// DatabaseConfig configures the Postgres connection and schema migrations
+type DatabaseConfig struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"sslmode"`
+
+	// TimeZone is the session time zone of database connections
+	TimeZone string `yaml:"timezone"`
+
+	// Connection pool limits
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+
+	// MigrateOnStart applies pending migrations when the server starts
+	MigrateOnStart bool `yaml:"migrate_on_start"`
+
+	// AutoMigrate also auto-migrates the models on start (local development only)
+	AutoMigrate bool `yaml:"auto_migrate"`
+}
+
+// DSN returns the Postgres connection string
+func (c DatabaseConfig) DSN() string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s",
+		c.Host, c.User, c.Password, c.Name, c.Port, c.SSLMode, c.TimeZone)
+}
+
+// FrappeConfig configures the Frappe integration
+type FrappeConfig struct {
+	URL       string        `yaml:"url"`
+	APIKey    string        `yaml:"api_key"`
+	APISecret string        `yaml:"api_secret"`
+	Timeout   time.Duration `yaml:"timeout"`
+
+	// WebhookSecret enables balance type updates pushed by Frappe
+	WebhookSecret string `yaml:"webhook_secret"`
+}
+
+// BalanceTypeConfig configures where balance types come from and how they are cached
+type BalanceTypeConfig struct {
+	// Backend is "frappe" or "local"
+	Backend string `yaml:"backend"`
+
+	// CacheTTL overrides how long the cached list stays fresh
+	CacheTTL time.Duration `yaml:"cache_ttl"`
+
+	// Scales holds the decimal places of Frappe balance types; types not
+	// listed use utils.DefaultBalanceScale. Configured values are added to
+	// the defaults, e.g. {"Gems": 0}.
+	Scales map[string]int32 `yaml:"scales"`
+
+	// Backfill adds pushed balance types to every wallet with a zero balance
+	Backfill bool `yaml:"backfill"`
+}
+
+// HoldConfig configures balance holds
+type HoldConfig struct {
+	TTL time.Duration `yaml:"ttl"`
+}
+
+// AuthConfig configures API authentication
+type AuthConfig struct {
+	// BootstrapAPIKey and BootstrapAPISecret create an admin client on start
+	BootstrapAPIKey    string `yaml:"bootstrap_api_key"`
+	BootstrapAPISecret string `yaml:"bootstrap_api_secret"`
+
+	// JWT verifies service tokens, UserJWT end-user tokens
+	JWT     JWTConfig `yaml:"jwt"`
+	UserJWT JWTConfig `yaml:"user_jwt"`
+}
+
+// JWTConfig configures verification of one kind of JWT. Verification is
+// disabled without a JWKS file.
+type JWTConfig struct {
+	JWKSFile string `yaml:"jwks_file"`
+	Issuer   string `yaml:"issuer"`
+	Audience string `yaml:"audience"`
+}
+
+// EventConfig configures where events from the outbox are sent
+type EventConfig struct {
+	// Sinks lists the event sinks: webhook, stdout and/or nats
+	Sinks []string `yaml:"sinks"`
+
+	NATSURL           string `yaml:"nats_url"`
+	NATSSubjectPrefix string `yaml:"nats_subject_prefix"`
+}
+
+// Default returns the settings used when nothing else is configured
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:               "8080",
+			ShutdownTimeout:    30 * time.Second,
+			HealthCheckTimeout: 2 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Host:            "localhost",
+			Port:            "5432",
+			User:            "postgres",
+			Name:            "ecommerce_marketplace",
+			SSLMode:         "disable",
+			TimeZone:        "UTC",
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			MigrateOnStart:  true,
+		},
+		Frappe: FrappeConfig{
+			Timeout: 10 * time.Second,
+		},
+		BalanceTypes: BalanceTypeConfig{
+			Backend: "frappe",
+			Scales:  map[string]int32{"Coins": 2, "Exp": 0},
+		},
+		Holds: HoldConfig{
+			TTL: 15 * time.Minute,
+		},
+		Events: EventConfig{
+			Sinks:             []string{"webhook"},
+			NATSSubjectPrefix: "wallet",
+		},
+	}
+}
+
+// Load reads the configuration. Variables from a .env file are added to the
+// environment first; CONFIG_FILE may point at a YAML file whose values are
+// in turn overridden by environment variables. The result is validated.
+func Load() (*Config, error) {
+	// .env bersifat opsional, variabel yang sudah ada tidak ditimpa
+	_ = godotenv.Load()
+
+	config := Default()
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := yaml.Unmarshal(content, config); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	var problems []string
+	env := envReader{problems: &problems}
+
+	env.String(&config.Server.Port, "PORT")
+	env.Duration(&config.Server.ShutdownTimeout, "SHUTDOWN_TIMEOUT")
+	env.Duration(&config.Server.ShutdownDelay, "SHUTDOWN_DELAY")
+	env.Duration(&config.Server.HealthCheckTimeout, "HEALTH_CHECK_TIMEOUT")
+
+	env.String(&config.Database.Host, "DB_HOST")
+	env.String(&config.Database.Port, "DB_PORT")
+	env.String(&config.Database.User, "DB_USER")
+	env.String(&config.Database.Password, "DB_PASSWORD")
+	env.String(&config.Database.Name, "DB_NAME")
+	env.String(&config.Database.SSLMode, "DB_SSLMODE")
+	env.String(&config.Database.TimeZone, "DB_TIMEZONE")
+	env.Int(&config.Database.MaxOpenConns, "DB_MAX_OPEN_CONNS")
+	env.Int(&config.Database.MaxIdleConns, "DB_MAX_IDLE_CONNS")
+	env.Duration(&config.Database.ConnMaxLifetime, "DB_CONN_MAX_LIFETIME")
+	env.Duration(&config.Database.ConnMaxIdleTime, "DB_CONN_MAX_IDLE_TIME")
+	env.Bool(&config.Database.MigrateOnStart, "DB_MIGRATE_ON_START")
+	env.Bool(&config.Database.AutoMigrate, "DB_AUTO_MIGRATE")
+
+	env.String(&config.Frappe.URL, "FRAPPE_URL")
+	env.String(&config.Frappe.APIKey, "FRAPPE_API_KEY")
+	env.String(&config.Frappe.APISecret, "FRAPPE_API_SECRET")
+	env.Duration(&config.Frappe.Timeout, "FRAPPE_TIMEOUT")
+	env.String(&config.Frappe.WebhookSecret, "FRAPPE_WEBHOOK_SECRET")
+
+	env.String(&config.BalanceTypes.Backend, "BALANCE_TYPE_BACKEND")
+	env.Duration(&config.BalanceTypes.CacheTTL, "BALANCE_TYPE_CACHE_TTL")
+	env.Scales(&config.BalanceTypes.Scales, "BALANCE_TYPE_SCALES")
+	env.Bool(&config.BalanceTypes.Backfill, "BALANCE_TYPE_BACKFILL")
+
+	env.Duration(&config.Holds.TTL, "HOLD_TTL")
+
+	env.String(&config.Auth.BootstrapAPIKey, "AUTH_BOOTSTRAP_API_KEY")
+	env.String(&config.Auth.BootstrapAPISecret, "AUTH_BOOTSTRAP_API_SECRET")
+	env.JWT(&config.Auth.JWT, "JWT")
+	env.JWT(&config.Auth.UserJWT, "USER_JWT")
+
+	env.List(&config.Events.Sinks, "EVENT_SINKS")
+	env.String(&config.Events.NATSURL, "NATS_URL")
+	env.String(&config.Events.NATSSubjectPrefix, "NATS_SUBJECT_PREFIX")
+
+	problems = append(problems, config.validate()...)
+	if len(problems) > 0 {
+		return nil, errors.New("invalid configuration:\n  - " + strings.Join(problems, "\n  - "))
+	}
+	return config, nil
+}
+
+// validate returns a description of every invalid or missing setting
+func (c *Config) validate() []string {
+	var problems []string
+	required := func(value, name string) {
+		if strings.TrimSpace(value) == "" {
+			problems = append(problems, name+" is required")
+		}
+	}
+
+	required(c.Server.Port, "PORT")
+	if c.Server.ShutdownTimeout <= 0 {
+		problems = append(problems, "SHUTDOWN_TIMEOUT must be positive")
+	}
+	if c.Server.ShutdownDelay < 0 {
+		problems = append(problems, "SHUTDOWN_DELAY must not be negative")
+	}
+	if c.Server.HealthCheckTimeout <= 0 {
+		problems = append(problems, "HEALTH_CHECK_TIMEOUT must be positive")
+	}
+
+	required(c.Database.Host, "DB_HOST")
+	required(c.Database.Port, "DB_PORT")
+	required(c.Database.User, "DB_USER")
+	required(c.Database.Password, "DB_PASSWORD")
+	required(c.Database.Name, "DB_NAME")
+	if _, err := time.LoadLocation(c.Database.TimeZone); err != nil {
+		problems = append(problems, fmt.Sprintf("DB_TIMEZONE: unknown time zone %q", c.Database.TimeZone))
+	}
+	// worker latar belakang (outbox, webhook, hold) butuh koneksi sendiri di samping request
+	if c.Database.MaxOpenConns < 2 {
+		problems = append(problems, "DB_MAX_OPEN_CONNS must be at least 2")
+	}
+	if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		problems = append(problems, "DB_MAX_IDLE_CONNS must be between 0 and DB_MAX_OPEN_CONNS")
+	}
+	if c.Database.ConnMaxLifetime < 0 || c.Database.ConnMaxIdleTime < 0 {
+		problems = append(problems, "DB_CONN_MAX_LIFETIME and DB_CONN_MAX_IDLE_TIME must not be negative")
+	}
+
+	switch c.BalanceTypes.Backend {
+	case "frappe":
+		required(c.Frappe.URL, "FRAPPE_URL")
+		required(c.Frappe.APIKey, "FRAPPE_API_KEY")
+		required(c.Frappe.APISecret, "FRAPPE_API_SECRET")
+	case "local":
+	default:
+		problems = append(problems, fmt.Sprintf("BALANCE_TYPE_BACKEND must be frappe or local, got %q", c.BalanceTypes.Backend))
+	}
+	if c.Frappe.Timeout <= 0 {
+		problems = append(problems, "FRAPPE_TIMEOUT must be positive")
+	}
+	if c.BalanceTypes.CacheTTL < 0 {
+		problems = append(problems, "BALANCE_TYPE_CACHE_TTL must not be negative")
+	}
+	for name, scale := range c.BalanceTypes.Scales {
+		if scale < 0 || scale > 18 {
+			problems = append(problems, fmt.Sprintf("BALANCE_TYPE_SCALES: scale of %s must be between 0 and 18", name))
+		}
+	}
+
+	if c.Holds.TTL <= 0 {
+		problems = append(problems, "HOLD_TTL must be positive")
+	}
+
+	if (c.Auth.BootstrapAPIKey == "") != (c.Auth.BootstrapAPISecret == "") {
+		problems = append(problems, "AUTH_BOOTSTRAP_API_KEY and AUTH_BOOTSTRAP_API_SECRET must be set together")
+	}
+
+	if len(c.Events.Sinks) == 0 {
+		problems = append(problems, "EVENT_SINKS must list at least one sink")
+	}
+	for _, sink := range c.Events.Sinks {
+		switch sink {
+		case "webhook", "stdout":
+		case "nats":
+			required(c.Events.NATSURL, "NATS_URL")
+			required(c.Events.NATSSubjectPrefix, "NATS_SUBJECT_PREFIX")
+		default:
+			problems = append(problems, fmt.Sprintf("EVENT_SINKS: unknown sink %q", sink))
+		}
+	}
+	return problems
+}
+
+// envReader overrides settings with environment variables that are set and
+// records values that cannot be parsed
+type envReader struct {
+	problems *[]string
+}
+
+func (r envReader) lookup(name string) (string, bool) {
+	value, ok := os.LookupEnv(name)
+	if !ok || strings.TrimSpace(value) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(value), true
+}
+
+func (r envReader) fail(name, format string, args ...interface{}) {
+	*r.problems = append(*r.problems, name+": "+fmt.Sprintf(format, args...))
+}
+
+func (r envReader) String(target *string, name string) {
+	if value, ok := r.lookup(name); ok {
+		*target = value
+	}
+}
+
+func (r envReader) Bool(target *bool, name string) {
+	if value, ok := r.lookup(name); ok {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			r.fail(name, "expected true or false, got %q", value)
+			return
+		}
+		*target = parsed
+	}
+}
+
+func (r envReader) Int(target *int, name string) {
+	if value, ok := r.lookup(name); ok {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			r.fail(name, "expected a whole number, got %q", value)
+			return
+		}
+		*target = parsed
+	}
+}
+
+func (r envReader) Duration(target *time.Duration, name string) {
+	if value, ok := r.lookup(name); ok {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			r.fail(name, "expected a duration such as 30s or 15m, got %q", value)
+			return
+		}
+		*target = parsed
+	}
+}
+
+// List reads a comma-separated list
+func (r envReader) List(target *[]string, name string) {
+	if value, ok := r.lookup(name); ok {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*target = items
+	}
+}
+
+// Scales reads balance type scales such as "Coins:2,Exp:0,Gems:0" and adds
+// them to the ones already in target
+func (r envReader) Scales(target *map[string]int32, name string) {
+	value, ok := r.lookup(name)
+	if !ok {
+		return
+	}
+
+	scales := make(map[string]int32, len(*target))
+	for balanceType, scale := range *target {
+		scales[balanceType] = scale
+	}
+	for _, pair := range strings.Split(value, ",") {
+		balanceType, scale, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			r.fail(name, "expected <type>:<scale>, got %q", pair)
+			continue
+		}
+		parsed, err := strconv.ParseInt(strings.TrimSpace(scale), 10, 32)
+		if err != nil {
+			r.fail(name, "invalid scale %q for %s", scale, balanceType)
+			continue
+		}
+		scales[strings.TrimSpace(balanceType)] = int32(parsed)
+	}
+	*target = scales
+}
+
+// JWT reads <prefix>_JWKS_FILE, <prefix>_ISSUER and <prefix>_AUDIENCE
+func (r envReader) JWT(target *JWTConfig, prefix string) {
+	r.String(&target.JWKSFile, prefix+"_JWKS_FILE")
+	r.String(&target.Issuer, prefix+"_ISSUER")
+	r.String(&target.Audience, prefix+"_AUDIENCE")
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestBalanceTypeScalesAddToDefaults(t *testing.T) {
+	config := Default()
+	if err := yaml.Unmarshal([]byte("balance_types:\n  scales: {Coins: 4}\n"), config); err != nil {
+		t.Fatalf("parse yaml: %v", err)
+	}
+
+	t.Setenv("BALANCE_TYPE_SCALES", "Gems:0")
+	var problems []string
+	envReader{problems: &problems}.Scales(&config.BalanceTypes.Scales, "BALANCE_TYPE_SCALES")
+	if len(problems) > 0 {
+		t.Fatalf("unexpected problems: %v", problems)
+	}
+
+	want := map[string]int32{"Coins": 4, "Exp": 0, "Gems": 0}
+	if !reflect.DeepEqual(config.BalanceTypes.Scales, want) {
+		t.Errorf("got scales %v, want %v", config.BalanceTypes.Scales, want)
+	}
+
+	// setiap Default punya map sendiri
+	if scale := Default().BalanceTypes.Scales["Coins"]; scale != 2 {
+		t.Errorf("default Coins scale = %d, want 2", scale)
+	}
+}
@@ -2,7 +2,8 @@ package config
 
 import (
 	"fmt"
-	"os"
+	"log"
+	"e-commerce_marketplace/internal/migrations"
 	"e-commerce_marketplace/internal/models"
 
 	"gorm.io/driver/postgres"
@@ -10,51 +11,67 @@ import (
 	"gorm.io/gorm/logger"
 )
 
-func InitDatabase() (*gorm.DB, error) {
-	// Database connection parameters
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	user := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
-	dbname := os.Getenv("DB_NAME")
-	sslmode := os.Getenv("DB_SSLMODE")
-
-	// Set defaults
-	if host == "" {
-		host = "localhost"
-	}
-	if port == "" {
-		port = "5432"
-	}
-	if user == "" {
-		user = "postgres"
-	}
-	if password == "" {
-		password = "postgresql"
+// InitDatabase connects to the database and brings its schema up to date.
+// Pending migrations are applied when MigrateOnStart is set; with AutoMigrate
+// the models are also auto-migrated on top (dev only).
+func InitDatabase(cfg DatabaseConfig) (*gorm.DB, error) {
+	db, err := OpenDatabase(cfg)
+	if err != nil {
+		return nil, err
 	}
-	if dbname == "" {
-		dbname = "ecommerce_marketplace"
+
+	if cfg.MigrateOnStart {
+		migrator, err := migrations.New(db)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load migrations: %w", err)
+		}
+		applied, err := migrator.Up()
+		for _, migration := range applied {
+			log.Printf("Applied migration %d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
 	}
-	if sslmode == "" {
-		sslmode = "disable"
+
+	// Auto migrate, only for local development
+	if cfg.AutoMigrate {
+		if err := db.AutoMigrate(&models.Wallet{}, &models.WalletBalance{}, &models.Transaction{}, &models.Hold{}, &models.BalanceType{}, &models.BalanceTypeAlias{}, &models.WalletStatusChange{}, &models.Batch{}, &models.BatchItem{}, &models.APIClient{}, &models.WebhookSubscription{}, &models.WebhookDelivery{}, &models.OutboxEvent{}); err != nil {
+			return nil, fmt.Errorf("failed to auto migrate database: %w", err)
+		}
 	}
 
-	// Build connection string
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=Asia/Jakarta",
-		host, user, password, dbname, port, sslmode)
+	return db, nil
+}
 
+// OpenDatabase connects to the database without touching its schema
+func OpenDatabase(cfg DatabaseConfig) (*gorm.DB, error) {
 	// Open database connection
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+	db, err := gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	// Auto migrate
-	if err := db.AutoMigrate(&models.Wallet{}); err != nil {
-		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	// Connection pool
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure connection pool: %w", err)
 	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
 	return db, nil
-}
\ No newline at end of file
+}
+
+// CloseDatabase closes all connections of the pool
+func CloseDatabase(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
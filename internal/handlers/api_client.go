@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"e-commerce_marketplace/internal/services"
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type APIClientHandler struct {
+	clientService services.APIClientService
+}
+
+// NewAPIClientHandler creates a new API client handler
+func NewAPIClientHandler(clientService services.APIClientService) *APIClientHandler {
+	return &APIClientHandler{
+		clientService: clientService,
+	}
+}
+
+// ListClients handles GET /admin/api-clients
+func (h *APIClientHandler) ListClients(c *fiber.Ctx) error {
+	clients, err := h.clientService.ListClients()
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "API clients retrieved successfully", clients)
+}
+
+// CreateClient handles POST /admin/api-clients
+func (h *APIClientHandler) CreateClient(c *fiber.Ctx) error {
+	var req utils.CreateAPIClientRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body", err.Error())
+	}
+
+	credentials, err := h.clientService.CreateClient(&req)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.CreatedResponse(c, "API client created successfully", credentials)
+}
+
+// UpdateClient handles PATCH /admin/api-clients/:id
+func (h *APIClientHandler) UpdateClient(c *fiber.Ctx) error {
+	var req utils.UpdateAPIClientRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body", err.Error())
+	}
+
+	client, err := h.clientService.UpdateClient(c.Params("id"), &req)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "API client updated successfully", client)
+}
+
+// RotateSecret handles POST /admin/api-clients/:id/rotate-secret
+func (h *APIClientHandler) RotateSecret(c *fiber.Ctx) error {
+	credentials, err := h.clientService.RotateSecret(c.Params("id"))
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "API client secret rotated successfully", credentials)
+}
+
+// DeleteClient handles DELETE /admin/api-clients/:id
+func (h *APIClientHandler) DeleteClient(c *fiber.Ctx) error {
+	if err := h.clientService.DeleteClient(c.Params("id")); err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "API client deleted successfully", nil)
+}
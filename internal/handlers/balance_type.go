@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"e-commerce_marketplace/internal/services"
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type BalanceTypeHandler struct {
+	balanceTypeService services.BalanceTypeService
+}
+
+// NewBalanceTypeHandler creates a new balance type handler
+func NewBalanceTypeHandler(balanceTypeService services.BalanceTypeService) *BalanceTypeHandler {
+	return &BalanceTypeHandler{
+		balanceTypeService: balanceTypeService,
+	}
+}
+
+// ListBalanceTypes handles GET /balance-types
+func (h *BalanceTypeHandler) ListBalanceTypes(c *fiber.Ctx) error {
+	balanceTypes, err := h.balanceTypeService.ListBalanceTypes()
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Balance types retrieved successfully", balanceTypes)
+}
+
+// GetBalanceType handles GET /balance-types/:name
+func (h *BalanceTypeHandler) GetBalanceType(c *fiber.Ctx) error {
+	balanceType, err := h.balanceTypeService.GetBalanceType(c.Params("name"))
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Balance type retrieved successfully", balanceType)
+}
+
+// CreateBalanceType handles POST /balance-types
+func (h *BalanceTypeHandler) CreateBalanceType(c *fiber.Ctx) error {
+	var req utils.CreateBalanceTypeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body", err.Error())
+	}
+
+	balanceType, err := h.balanceTypeService.CreateBalanceType(&req)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.CreatedResponse(c, "Balance type created successfully", balanceType)
+}
+
+// UpdateBalanceType handles PUT /balance-types/:name
+func (h *BalanceTypeHandler) UpdateBalanceType(c *fiber.Ctx) error {
+	var req utils.UpdateBalanceTypeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body", err.Error())
+	}
+
+	balanceType, err := h.balanceTypeService.UpdateBalanceType(c.Params("name"), &req)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Balance type updated successfully", balanceType)
+}
+
+// DeleteBalanceType handles DELETE /balance-types/:name
+func (h *BalanceTypeHandler) DeleteBalanceType(c *fiber.Ctx) error {
+	if err := h.balanceTypeService.DeleteBalanceType(c.Params("name")); err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Balance type deleted successfully", nil)
+}
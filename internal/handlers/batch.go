@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"strings"
+
+	"e-commerce_marketplace/internal/services"
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type BatchHandler struct {
+	batchService services.BatchService
+}
+
+// NewBatchHandler creates a new batch handler
+func NewBatchHandler(batchService services.BatchService) *BatchHandler {
+	return &BatchHandler{
+		batchService: batchService,
+	}
+}
+
+// CreateBatch handles POST /batches. Rows are sent as JSON or uploaded as a
+// CSV file in the multipart field "file".
+func (h *BatchHandler) CreateBatch(c *fiber.Ctx) error {
+	var req utils.CreateBatchRequest
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return utils.BadRequestResponse(c, "CSV file is required", err.Error())
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return utils.BadRequestResponse(c, "Invalid CSV file", err.Error())
+		}
+		defer file.Close()
+
+		if req.Items, err = utils.ParseBatchCSV(file); err != nil {
+			return handleServiceError(c, err)
+		}
+		req.Reference = c.FormValue("reference")
+		req.IdempotencyKey = c.FormValue("idempotency_key")
+	} else if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body", err.Error())
+	}
+
+	if key := c.Get(idempotencyKeyHeader); key != "" {
+		req.IdempotencyKey = key
+	}
+
+	result, err := h.batchService.SubmitBatch(&req)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+	if result.Replayed {
+		c.Set(idempotentReplayHeader, "true")
+		return utils.SuccessResponse(c, "Batch resubmitted successfully", result.Batch)
+	}
+
+	return utils.AcceptedResponse(c, "Batch accepted for processing", result.Batch)
+}
+
+// GetBatch handles GET /batches/:id
+func (h *BatchHandler) GetBatch(c *fiber.Ctx) error {
+	batch, err := h.batchService.GetBatch(c.Params("id"))
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Batch retrieved successfully", batch)
+}
+
+// ListBatchItems handles GET /batches/:id/items
+func (h *BatchHandler) ListBatchItems(c *fiber.Ctx) error {
+	var req utils.ListBatchItemsRequest
+	if err := c.QueryParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid query parameters", err.Error())
+	}
+
+	page, err := h.batchService.ListBatchItems(c.Params("id"), &req)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Batch items retrieved successfully", page)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"e-commerce_marketplace/pkg/utils"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// handleServiceError converts service errors to appropriate HTTP responses
+func handleServiceError(c *fiber.Ctx, err error) error {
+	if utils.IsWalletError(err) {
+		walletErr := err.(*utils.WalletError)
+		switch walletErr.Code {
+		case utils.CodeWalletNotFound, utils.CodeTransactionNotFound, utils.CodeHoldNotFound, utils.CodeBalanceTypeNotFound, utils.CodeBatchNotFound, utils.CodeAPIClientNotFound,
+			utils.CodeWebhookNotFound, utils.CodeWebhookDeliveryNotFound:
+			return utils.NotFoundResponse(c, walletErr.Message)
+		case utils.CodeWalletExists, utils.CodeBalanceTypeExists, utils.CodeAPIClientExists:
+			return utils.ConflictResponse(c, walletErr.Message)
+		case utils.CodeIdempotencyConflict, utils.CodeHoldNotActive, utils.CodeWalletNotEmpty, utils.CodeInvalidStatusTransition:
+			return utils.ErrorResponse(c, fiber.StatusConflict, walletErr.Message, walletErr.Details)
+		case utils.CodeInsufficientBalance:
+			return utils.BadRequestResponse(c, walletErr.Message, walletErr.Details)
+		case utils.CodeTransactionNotReversible, utils.CodeReversalExceedsOriginal, utils.CodeBalanceTypeNotTransferable:
+			return utils.ErrorResponse(c, fiber.StatusUnprocessableEntity, walletErr.Message, walletErr.Details)
+		case utils.CodeWalletNotActive:
+			return utils.ErrorResponse(c, fiber.StatusForbidden, walletErr.Message, walletErr.Details)
+		case utils.CodeBalanceTypeReadOnly:
+			return utils.ErrorResponse(c, fiber.StatusMethodNotAllowed, walletErr.Message, walletErr.Details)
+		case utils.CodeInvalidAmount, utils.CodeInvalidBalanceType, utils.CodeValidationError:
+			return utils.BadRequestResponse(c, walletErr.Message, walletErr.Details)
+		case utils.CodeBalanceTypeUnavailable, utils.CodeShuttingDown:
+			return utils.ServiceUnavailableResponse(c, walletErr.Message)
+		case utils.CodeDatabaseError, utils.CodeInternalError:
+			// detail error hanya untuk log, tidak dikirim ke client
+			log.Printf("[%s %s] %s: %s (%s)", c.Method(), c.Path(), walletErr.Code, walletErr.Message, walletErr.Details)
+			return utils.InternalServerErrorResponse(c, "An error occurred while processing your request")
+		default:
+			// ada error tapi kita gak tau → balikin generic
+			return utils.InternalServerErrorResponse(c, "An error occurred while processing your request")
+		}
+	}
+
+	// kalau error bukan WalletError, anggap unexpected
+	return utils.InternalServerErrorResponse(c, "An unexpected error occurred")
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"e-commerce_marketplace/internal/services"
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// frappeSignatureHeader carries the signature of webhooks sent by Frappe
+const frappeSignatureHeader = "X-Frappe-Webhook-Signature"
+
+type FrappeHandler struct {
+	balanceTypeSyncService services.BalanceTypeSyncService
+	webhookSecret          string
+}
+
+// NewFrappeHandler creates a new handler for webhooks sent by Frappe.
+// Requests must be signed with webhookSecret.
+func NewFrappeHandler(balanceTypeSyncService services.BalanceTypeSyncService, webhookSecret string) *FrappeHandler {
+	return &FrappeHandler{
+		balanceTypeSyncService: balanceTypeSyncService,
+		webhookSecret:          webhookSecret,
+	}
+}
+
+// BalanceTypeEvent handles POST /integrations/frappe/balance-types
+func (h *FrappeHandler) BalanceTypeEvent(c *fiber.Ctx) error {
+	if !utils.VerifyFrappeSignature(h.webhookSecret, c.Body(), c.Get(frappeSignatureHeader)) {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, "Invalid webhook signature", nil)
+	}
+
+	var req utils.FrappeBalanceTypeEvent
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body", err.Error())
+	}
+
+	balanceType, err := h.balanceTypeSyncService.HandleFrappeEvent(&req)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Balance type event processed successfully", balanceType)
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"e-commerce_marketplace/internal/services"
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HealthHandler struct {
+	healthService services.HealthService
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(healthService services.HealthService) *HealthHandler {
+	return &HealthHandler{
+		healthService: healthService,
+	}
+}
+
+// Health handles GET /healthz. It only tells that the process is up.
+func (h *HealthHandler) Health(c *fiber.Ctx) error {
+	return utils.SuccessResponse(c, "Service is alive", fiber.Map{"status": "ok"})
+}
+
+// Ready handles GET /readyz
+func (h *HealthHandler) Ready(c *fiber.Ctx) error {
+	report := h.healthService.Readiness(c.UserContext())
+	if !report.Ready {
+		return utils.ErrorResponse(c, fiber.StatusServiceUnavailable, "Service is not ready", report)
+	}
+
+	return utils.SuccessResponse(c, "Service is ready", report)
+}
@@ -3,12 +3,19 @@ package handlers
 import (
 	"e-commerce_marketplace/internal/services"
 	"e-commerce_marketplace/pkg/utils"
-	"fmt"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
+const (
+	// idempotencyKeyHeader lets callers retry credits and debits safely
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	// idempotentReplayHeader marks responses served from an earlier request
+	idempotentReplayHeader = "Idempotent-Replayed"
+)
+
 type WalletHandler struct {
 	walletService services.WalletService
 }
@@ -22,11 +29,21 @@ func NewWalletHandler(walletService services.WalletService) *WalletHandler {
 
 // CreateWallet handles POST /wallets
 func (h *WalletHandler) CreateWallet(c *fiber.Ctx) error {
-	userID := uuid.New().String()
+	var req utils.CreateWalletRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.BadRequestResponse(c, "Invalid request body", err.Error())
+		}
+	}
+
+	// tanpa wallet_user_id: generate UUID seperti sebelumnya
+	if req.WalletUserID == "" {
+		req.WalletUserID = uuid.New().String()
+	}
 
-	wallet, err := h.walletService.CreateWallet(userID)
+	wallet, err := h.walletService.CreateWallet(&req)
 	if err != nil {
-		return h.handleServiceError(c, err)
+		return handleServiceError(c, err)
 	}
 
 	return utils.CreatedResponse(c, "Wallet created successfully", wallet)
@@ -41,12 +58,62 @@ func (h *WalletHandler) GetWallet(c *fiber.Ctx) error {
 
 	wallet, err := h.walletService.GetWallet(walletUserID)
 	if err != nil {
-		return h.handleServiceError(c, err)
+		return handleServiceError(c, err)
 	}
 
 	return utils.SuccessResponse(c, "Wallet retrieved successfully", wallet)
 }
 
+// ListUserWallets handles GET /users/:userId/wallets
+func (h *WalletHandler) ListUserWallets(c *fiber.Ctx) error {
+	ownerID := c.Params("userId")
+	if ownerID == "" {
+		return utils.BadRequestResponse(c, "User ID is required", "")
+	}
+
+	wallets, err := h.walletService.ListUserWallets(ownerID)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Wallets retrieved successfully", wallets)
+}
+
+// ChangeWalletStatus handles PUT /admin/wallets/:id/status
+func (h *WalletHandler) ChangeWalletStatus(c *fiber.Ctx) error {
+	walletUserID := c.Params("id")
+	if walletUserID == "" {
+		return utils.BadRequestResponse(c, "Wallet user ID is required", "")
+	}
+
+	var req utils.ChangeWalletStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body", err.Error())
+	}
+
+	change, err := h.walletService.ChangeWalletStatus(walletUserID, &req)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Wallet status changed successfully", change)
+}
+
+// ListWalletStatusChanges handles GET /admin/wallets/:id/status-history
+func (h *WalletHandler) ListWalletStatusChanges(c *fiber.Ctx) error {
+	walletUserID := c.Params("id")
+	if walletUserID == "" {
+		return utils.BadRequestResponse(c, "Wallet user ID is required", "")
+	}
+
+	changes, err := h.walletService.ListWalletStatusChanges(walletUserID)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Wallet status history retrieved successfully", changes)
+}
+
 // AddBalance handles POST /wallets/:id/add
 func (h *WalletHandler) AddBalance(c *fiber.Ctx) error {
 	walletUserID := c.Params("id")
@@ -59,12 +126,19 @@ func (h *WalletHandler) AddBalance(c *fiber.Ctx) error {
 		return utils.BadRequestResponse(c, "Invalid request body", err.Error())
 	}
 
-	wallet, err := h.walletService.AddBalance(walletUserID, &req)
+	if key := c.Get(idempotencyKeyHeader); key != "" {
+		req.IdempotencyKey = key
+	}
+
+	result, err := h.walletService.AddBalance(walletUserID, &req)
 	if err != nil {
-		return h.handleServiceError(c, err)
+		return handleServiceError(c, err)
+	}
+	if result.Replayed {
+		c.Set(idempotentReplayHeader, "true")
 	}
 
-	return utils.SuccessResponse(c, "Balance added successfully", wallet)
+	return utils.SuccessResponse(c, "Balance added successfully", result)
 }
 
 // DeductBalance handles POST /wallets/:id/deduct
@@ -79,36 +153,142 @@ func (h *WalletHandler) DeductBalance(c *fiber.Ctx) error {
 		return utils.BadRequestResponse(c, "Invalid request body", err.Error())
 	}
 
-	wallet, err := h.walletService.DeductBalance(walletUserID, &req)
+	if key := c.Get(idempotencyKeyHeader); key != "" {
+		req.IdempotencyKey = key
+	}
+
+	result, err := h.walletService.DeductBalance(walletUserID, &req)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+	if result.Replayed {
+		c.Set(idempotentReplayHeader, "true")
+	}
+
+	return utils.SuccessResponse(c, "Balance deducted successfully", result)
+}
+
+// ListTransactions handles GET /wallets/:id/transactions
+func (h *WalletHandler) ListTransactions(c *fiber.Ctx) error {
+	walletUserID := c.Params("id")
+	if walletUserID == "" {
+		return utils.BadRequestResponse(c, "Wallet user ID is required", "")
+	}
+
+	var req utils.ListTransactionsRequest
+	if err := c.QueryParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid query parameters", err.Error())
+	}
+
+	page, err := h.walletService.ListTransactions(walletUserID, &req)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Transactions retrieved successfully", page)
+}
+
+// Transfer handles POST /transfers
+func (h *WalletHandler) Transfer(c *fiber.Ctx) error {
+	var req utils.TransferRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body", err.Error())
+	}
+
+	if key := c.Get(idempotencyKeyHeader); key != "" {
+		req.IdempotencyKey = key
+	}
+
+	result, err := h.walletService.Transfer(&req)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+	if result.Replayed {
+		c.Set(idempotentReplayHeader, "true")
+		return utils.SuccessResponse(c, "Transfer completed successfully", result)
+	}
+
+	return utils.CreatedResponse(c, "Transfer completed successfully", result)
+}
+
+// CreateHold handles POST /wallets/:id/holds
+func (h *WalletHandler) CreateHold(c *fiber.Ctx) error {
+	walletUserID := c.Params("id")
+	if walletUserID == "" {
+		return utils.BadRequestResponse(c, "Wallet user ID is required", "")
+	}
+
+	var req utils.CreateHoldRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body", err.Error())
+	}
+
+	hold, err := h.walletService.CreateHold(walletUserID, &req)
 	if err != nil {
-		return h.handleServiceError(c, err)
-	}
-
-	return utils.SuccessResponse(c, "Balance deducted successfully", wallet)
-}
-
-// handleServiceError converts service errors to appropriate HTTP responses
-func (h *WalletHandler) handleServiceError(c *fiber.Ctx, err error) error {
-	// DEBUG: log full error ke terminal
-	fmt.Printf("[WalletHandler] error: %+v\n", err)
-
-	if utils.IsWalletError(err) {
-		walletErr := err.(*utils.WalletError)
-		switch walletErr.Code {
-		case utils.CodeWalletNotFound:
-			return utils.NotFoundResponse(c, walletErr.Message)
-		case utils.CodeWalletExists:
-			return utils.ConflictResponse(c, walletErr.Message)
-		case utils.CodeInsufficientBalance:
-			return utils.BadRequestResponse(c, walletErr.Message, walletErr.Details)
-		case utils.CodeInvalidAmount, utils.CodeInvalidBalanceType, utils.CodeValidationError:
-			return utils.BadRequestResponse(c, walletErr.Message, walletErr.Details)
-		default:
-			// ada error tapi kita gak tau → balikin generic
-			return utils.InternalServerErrorResponse(c, "An error occurred while processing your request")
+		return handleServiceError(c, err)
+	}
+
+	return utils.CreatedResponse(c, "Hold created successfully", hold)
+}
+
+// GetHold handles GET /wallets/:id/holds/:holdId
+func (h *WalletHandler) GetHold(c *fiber.Ctx) error {
+	hold, err := h.walletService.GetHold(c.Params("id"), c.Params("holdId"))
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Hold retrieved successfully", hold)
+}
+
+// CaptureHold handles POST /wallets/:id/holds/:holdId/capture
+func (h *WalletHandler) CaptureHold(c *fiber.Ctx) error {
+	var req utils.CaptureHoldRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.BadRequestResponse(c, "Invalid request body", err.Error())
 		}
 	}
 
-	// kalau error bukan WalletError, anggap unexpected
-	return utils.InternalServerErrorResponse(c, "An unexpected error occurred")
+	result, err := h.walletService.CaptureHold(c.Params("id"), c.Params("holdId"), &req)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Hold captured successfully", result)
+}
+
+// ReleaseHold handles POST /wallets/:id/holds/:holdId/release
+func (h *WalletHandler) ReleaseHold(c *fiber.Ctx) error {
+	hold, err := h.walletService.ReleaseHold(c.Params("id"), c.Params("holdId"))
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Hold released successfully", hold)
+}
+
+// ReverseTransaction handles POST /transactions/:txId/reverse
+func (h *WalletHandler) ReverseTransaction(c *fiber.Ctx) error {
+	var req utils.ReverseTransactionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.BadRequestResponse(c, "Invalid request body", err.Error())
+		}
+	}
+
+	if key := c.Get(idempotencyKeyHeader); key != "" {
+		req.IdempotencyKey = key
+	}
+
+	result, err := h.walletService.ReverseTransaction(c.Params("txId"), &req)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+	if result.Replayed {
+		c.Set(idempotentReplayHeader, "true")
+		return utils.SuccessResponse(c, "Transaction reversed successfully", result)
+	}
+
+	return utils.CreatedResponse(c, "Transaction reversed successfully", result)
 }
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"e-commerce_marketplace/internal/services"
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type WebhookHandler struct {
+	webhookService services.WebhookService
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookService services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// ListSubscriptions handles GET /webhooks
+func (h *WebhookHandler) ListSubscriptions(c *fiber.Ctx) error {
+	subscriptions, err := h.webhookService.ListSubscriptions()
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Webhook subscriptions retrieved successfully", subscriptions)
+}
+
+// CreateSubscription handles POST /webhooks
+func (h *WebhookHandler) CreateSubscription(c *fiber.Ctx) error {
+	var req utils.CreateWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body", err.Error())
+	}
+
+	credentials, err := h.webhookService.CreateSubscription(&req)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.CreatedResponse(c, "Webhook subscription created successfully", credentials)
+}
+
+// GetSubscription handles GET /webhooks/:id
+func (h *WebhookHandler) GetSubscription(c *fiber.Ctx) error {
+	subscription, err := h.webhookService.GetSubscription(c.Params("id"))
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Webhook subscription retrieved successfully", subscription)
+}
+
+// UpdateSubscription handles PATCH /webhooks/:id
+func (h *WebhookHandler) UpdateSubscription(c *fiber.Ctx) error {
+	var req utils.UpdateWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body", err.Error())
+	}
+
+	subscription, err := h.webhookService.UpdateSubscription(c.Params("id"), &req)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Webhook subscription updated successfully", subscription)
+}
+
+// DeleteSubscription handles DELETE /webhooks/:id
+func (h *WebhookHandler) DeleteSubscription(c *fiber.Ctx) error {
+	if err := h.webhookService.DeleteSubscription(c.Params("id")); err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Webhook subscription deleted successfully", nil)
+}
+
+// ListDeliveries handles GET /webhooks/:id/deliveries
+func (h *WebhookHandler) ListDeliveries(c *fiber.Ctx) error {
+	var req utils.ListWebhookDeliveriesRequest
+	if err := c.QueryParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid query parameters", err.Error())
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(c.Params("id"), &req)
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Webhook deliveries retrieved successfully", deliveries)
+}
+
+// Redeliver handles POST /webhooks/deliveries/:deliveryId/redeliver
+func (h *WebhookHandler) Redeliver(c *fiber.Ctx) error {
+	delivery, err := h.webhookService.Redeliver(c.Params("deliveryId"))
+	if err != nil {
+		return handleServiceError(c, err)
+	}
+
+	return utils.SuccessResponse(c, "Webhook delivery queued successfully", delivery)
+}
@@ -0,0 +1,114 @@
+// Package metrics holds the Prometheus metrics of the wallet service. They
+// are registered in Registry and exposed by Handler.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "wallet"
+
+// Balance operation outcomes
+const (
+	OutcomeSuccess  = "success"
+	OutcomeReplayed = "replayed"
+	OutcomeRejected = "rejected"
+	OutcomeError    = "error"
+)
+
+// Registry holds all metrics of the service, plus Go runtime and process metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	balanceOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "balance_operations_total",
+		Help:      "Credits, debits and transfers by balance type and outcome.",
+	}, []string{"operation", "balance_type", "outcome"})
+
+	balanceRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "balance_rejections_total",
+		Help:      "Rejected credits, debits and transfers by error code, e.g. INSUFFICIENT_BALANCE.",
+	}, []string{"operation", "code"})
+
+	frappeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "frappe_request_duration_seconds",
+		Help:      "Latency of calls to Frappe by operation.",
+		Buckets:   []float64{.025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"operation"})
+
+	frappeErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "frappe_request_errors_total",
+		Help:      "Failed calls to Frappe by operation.",
+	}, []string{"operation"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		balanceOperations,
+		balanceRejections,
+		frappeDuration,
+		frappeErrors,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RegisterDBStats exposes the connection pool statistics of db
+func RegisterDBStats(db *sql.DB) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// ObserveHTTPRequest records one served HTTP request
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// RecordBalanceOperation records the outcome of a credit, debit or transfer
+func RecordBalanceOperation(operation, balanceType, outcome string) {
+	balanceOperations.WithLabelValues(operation, balanceType, outcome).Inc()
+}
+
+// RecordBalanceRejection records a credit, debit or transfer rejected with code
+func RecordBalanceRejection(operation, code string) {
+	balanceRejections.WithLabelValues(operation, code).Inc()
+}
+
+// ObserveFrappeRequest records one call to Frappe
+func ObserveFrappeRequest(operation string, duration time.Duration, err error) {
+	frappeDuration.WithLabelValues(operation).Observe(duration.Seconds())
+	if err != nil {
+		frappeErrors.WithLabelValues(operation).Inc()
+	}
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"strings"
+
+	"e-commerce_marketplace/internal/services"
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// principalKey is the fiber.Ctx locals key of the authenticated caller
+const principalKey = "principal"
+
+// Authenticate rejects requests without valid credentials. Callers send
+// either "Authorization: token <api_key>:<api_secret>" (the Frappe token
+// scheme) or "Authorization: Bearer <jwt>".
+func Authenticate(authService services.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		scheme, credentials, _ := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+		credentials = strings.TrimSpace(credentials)
+
+		var principal *services.Principal
+		var err error
+		switch strings.ToLower(scheme) {
+		case "token":
+			apiKey, apiSecret, ok := strings.Cut(credentials, ":")
+			if !ok {
+				return unauthorized(c, "Malformed API token")
+			}
+			principal, err = authService.AuthenticateToken(apiKey, apiSecret)
+		case "bearer":
+			principal, err = authService.AuthenticateJWT(credentials)
+		default:
+			return unauthorized(c, "Authentication required")
+		}
+
+		if err != nil {
+			if utils.GetErrorCode(err) == utils.CodeUnauthorized {
+				return unauthorized(c, err.(*utils.WalletError).Message)
+			}
+			return utils.InternalServerErrorResponse(c, "An error occurred while authenticating the request")
+		}
+
+		c.Locals(principalKey, principal)
+		return c.Next()
+	}
+}
+
+// RequireScope rejects callers that were not granted scope
+func RequireScope(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal := GetPrincipal(c)
+		if principal == nil {
+			return unauthorized(c, "Authentication required")
+		}
+		if !principal.HasScope(scope) {
+			return utils.ErrorResponse(c, fiber.StatusForbidden, "Missing required scope", scope)
+		}
+		return c.Next()
+	}
+}
+
+// RequireScopeOrWalletOwner allows callers with scope and end users reading a
+// wallet they own. The wallet is taken from the ":id" route parameter; to end
+// users, wallets of other owners look like they don't exist.
+func RequireScopeOrWalletOwner(scope string, walletService services.WalletService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal := GetPrincipal(c)
+		if principal == nil {
+			return unauthorized(c, "Authentication required")
+		}
+		if principal.HasScope(scope) {
+			return c.Next()
+		}
+		if !principal.IsUser() {
+			return utils.ErrorResponse(c, fiber.StatusForbidden, "Missing required scope", scope)
+		}
+
+		wallet, err := walletService.GetWallet(c.Params("id"))
+		if err != nil && utils.GetErrorCode(err) != utils.CodeWalletNotFound {
+			return utils.InternalServerErrorResponse(c, "An error occurred while processing your request")
+		}
+		if err != nil || wallet.OwnerID != principal.UserID {
+			return utils.NotFoundResponse(c, "Wallet not found")
+		}
+		return c.Next()
+	}
+}
+
+// RequireScopeOrSelf allows callers with scope and end users whose ID is the
+// given route parameter
+func RequireScopeOrSelf(scope, param string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal := GetPrincipal(c)
+		if principal == nil {
+			return unauthorized(c, "Authentication required")
+		}
+		if principal.HasScope(scope) || (principal.IsUser() && principal.UserID == c.Params(param)) {
+			return c.Next()
+		}
+		return utils.ErrorResponse(c, fiber.StatusForbidden, "Missing required scope", scope)
+	}
+}
+
+// GetPrincipal returns the authenticated caller of the request, if any
+func GetPrincipal(c *fiber.Ctx) *services.Principal {
+	principal, _ := c.Locals(principalKey).(*services.Principal)
+	return principal
+}
+
+func unauthorized(c *fiber.Ctx, message string) error {
+	c.Set(fiber.HeaderWWWAuthenticate, `Bearer, token`)
+	return utils.ErrorResponse(c, fiber.StatusUnauthorized, message, nil)
+}
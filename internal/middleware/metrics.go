@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"errors"
+	"time"
+
+	"e-commerce_marketplace/internal/metrics"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Metrics records the count and latency of every request by its route
+// pattern, e.g. "/api/v1/wallets/:id/add", so wallet IDs do not
+// become metric labels. Requests that match no route are reported as
+// "unmatched".
+func Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		startedAt := time.Now()
+		err := c.Next()
+
+		route := c.Route().Path
+		status := c.Response().StatusCode()
+		if err != nil {
+			// error handler fiber belum menulis status ke response
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+			// router fiber mengembalikan 404 sebagai error bila tidak ada route
+			if status == fiber.StatusNotFound {
+				route = "unmatched"
+			}
+		}
+
+		metrics.ObserveHTTPRequest(c.Method(), route, status, time.Since(startedAt))
+		return err
+	}
+}
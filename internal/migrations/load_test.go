@@ -0,0 +1,54 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := load(files)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	for i, migration := range migrations {
+		if migration.Down == "" {
+			t.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+		}
+		if i > 0 && migration.Version <= migrations[i-1].Version {
+			t.Errorf("migration %d is out of order", migration.Version)
+		}
+	}
+}
+
+func TestLoadRejectsBadFiles(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"invalid name": {"sql/init.sql": {Data: []byte("SELECT 1;")}},
+		"missing up":   {"sql/0001_init.down.sql": {Data: []byte("SELECT 1;")}},
+		"two names": {
+			"sql/0001_init.up.sql":    {Data: []byte("SELECT 1;")},
+			"sql/0001_other.down.sql": {Data: []byte("SELECT 1;")},
+		},
+	}
+	for name, fsys := range tests {
+		if _, err := load(fsys); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	migrations, err := load(fstest.MapFS{
+		"sql/0002_b.up.sql":   {Data: []byte("SELECT 2;")},
+		"sql/0001_a.up.sql":   {Data: []byte("SELECT 1;")},
+		"sql/0001_a.down.sql": {Data: []byte("SELECT -1;")},
+	})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(migrations) != 2 || migrations[0].Name != "a" || migrations[1].Name != "b" ||
+		!strings.Contains(migrations[0].Down, "-1") {
+		t.Errorf("unexpected migrations: %+v", migrations)
+	}
+}
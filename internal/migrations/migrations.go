@@ -0,0 +1,257 @@
+// Package migrations applies the versioned SQL migrations embedded in sql/.
+// Files are named <version>_<name>.up.sql and <version>_<name>.down.sql;
+// applied versions are recorded in the schema_migrations table.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// migrationLockID is the Postgres advisory lock key that serializes
+// migrations across replicas starting at the same time
+const migrationLockID = 7_302_021
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one versioned schema change
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status reports whether a migration has been applied
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// appliedMigration is a row of the schema_migrations table
+type appliedMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for the appliedMigration model
+func (appliedMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrator applies and rolls back migrations. Every migration runs in its own
+// transaction together with its schema_migrations row, so a failed migration
+// leaves nothing behind; statements that cannot run in a transaction (e.g.
+// CREATE INDEX CONCURRENTLY) are not supported.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New creates a migrator for the embedded migrations
+func New(db *gorm.DB) (*Migrator, error) {
+	migrations, err := load(files)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// load reads the migrations from fsys, ordered by version
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, path.Join("sql", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %q and %q", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up applies all pending migrations in order and returns the ones applied
+func (m *Migrator) Up() ([]Migration, error) {
+	var applied []Migration
+	for _, migration := range m.migrations {
+		ran := false
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			versions, err := lock(tx)
+			if err != nil {
+				return err
+			}
+			// replika lain mungkin sudah menjalankannya selagi kita menunggu lock
+			if _, ok := versions[migration.Version]; ok {
+				return nil
+			}
+
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+			ran = true
+			return tx.Create(&appliedMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return applied, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		if ran {
+			applied = append(applied, migration)
+		}
+	}
+	return applied, nil
+}
+
+// Down rolls back the last steps applied migrations, newest first, and
+// returns the ones rolled back
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	var rolledBack []Migration
+	for i := 0; i < steps; i++ {
+		var migration *Migration
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			versions, err := lock(tx)
+			if err != nil {
+				return err
+			}
+
+			for j := len(m.migrations) - 1; j >= 0; j-- {
+				if _, ok := versions[m.migrations[j].Version]; ok {
+					migration = &m.migrations[j]
+					break
+				}
+			}
+			if migration == nil {
+				return nil
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+			}
+
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&appliedMigration{}, "version = ?", migration.Version).Error
+		})
+		if err != nil {
+			if migration == nil {
+				return rolledBack, err
+			}
+			return rolledBack, fmt.Errorf("rollback of migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		if migration == nil {
+			break
+		}
+		rolledBack = append(rolledBack, *migration)
+	}
+	return rolledBack, nil
+}
+
+// Status lists all known migrations with the time they were applied
+func (m *Migrator) Status() ([]Status, error) {
+	var versions map[int64]time.Time
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		versions, err = lock(tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if appliedAt, ok := versions[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pending returns the number of migrations that have not been applied
+func (m *Migrator) Pending() (int, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// lock takes the migration lock for the rest of tx, creates the
+// schema_migrations table if needed and returns the applied versions
+func lock(tx *gorm.DB) (map[int64]time.Time, error) {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockID).Error; err != nil {
+		return nil, fmt.Errorf("acquire migration lock: %w", err)
+	}
+	if err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version bigint PRIMARY KEY,
+			name text NOT NULL,
+			applied_at timestamptz NOT NULL
+		)`).Error; err != nil {
+		return nil, fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	var rows []appliedMigration
+	if err := tx.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	versions := make(map[int64]time.Time, len(rows))
+	for _, row := range rows {
+		versions[row.Version] = row.AppliedAt
+	}
+	return versions, nil
+}
@@ -0,0 +1,154 @@
+package migrations_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"e-commerce_marketplace/internal/migrations"
+	"e-commerce_marketplace/internal/testdb"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// legacyWallet is the wallet model as the original AutoMigrate created it
+type legacyWallet struct {
+	WalletUserID string `gorm:"unique;not null;index"`
+	Balances     datatypes.JSON
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+}
+
+func (legacyWallet) TableName() string {
+	return "wallets"
+}
+
+func TestUpgradesLegacySchema(t *testing.T) {
+	db := testdb.OpenEmpty(t)
+
+	if err := db.AutoMigrate(&legacyWallet{}); err != nil {
+		t.Fatalf("create legacy schema: %v", err)
+	}
+	legacy := []legacyWallet{
+		{WalletUserID: "user-1", Balances: datatypes.JSON(`{"Coins": 12.5, "Exp": 3}`)},
+		{WalletUserID: "user-2"},
+	}
+	if err := db.Create(&legacy).Error; err != nil {
+		t.Fatalf("insert legacy wallets: %v", err)
+	}
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+
+	var wallets []struct {
+		ID           string
+		WalletUserID string
+		OwnerID      string
+		Kind         string
+		Status       string
+	}
+	if err := db.Table("wallets").Order("wallet_user_id").Find(&wallets).Error; err != nil {
+		t.Fatalf("read wallets: %v", err)
+	}
+	if len(wallets) != 2 {
+		t.Fatalf("got %d wallets, want 2", len(wallets))
+	}
+	for _, wallet := range wallets {
+		if wallet.ID == "" {
+			t.Errorf("wallet %s has no id", wallet.WalletUserID)
+		}
+		if wallet.OwnerID != wallet.WalletUserID {
+			t.Errorf("wallet %s has owner %q", wallet.WalletUserID, wallet.OwnerID)
+		}
+		if wallet.Kind != "customer" || wallet.Status != "active" {
+			t.Errorf("wallet %s has kind %q and status %q", wallet.WalletUserID, wallet.Kind, wallet.Status)
+		}
+	}
+
+	var primaryKeys int64
+	db.Raw("SELECT COUNT(*) FROM pg_constraint WHERE conrelid = 'wallets'::regclass AND contype = 'p'").Scan(&primaryKeys)
+	if primaryKeys != 1 {
+		t.Errorf("wallets has %d primary keys, want 1", primaryKeys)
+	}
+
+	var balances []struct {
+		BalanceType string
+		Amount      decimal.Decimal
+		Held        decimal.Decimal
+	}
+	if err := db.Table("wallet_balances").Where("wallet_user_id = ?", "user-1").Order("balance_type").Find(&balances).Error; err != nil {
+		t.Fatalf("read balances: %v", err)
+	}
+	if len(balances) != 2 ||
+		balances[0].BalanceType != "Coins" || !balances[0].Amount.Equal(decimal.RequireFromString("12.5")) ||
+		balances[1].BalanceType != "Exp" || !balances[1].Amount.Equal(decimal.NewFromInt(3)) {
+		t.Errorf("unexpected backfilled balances: %+v", balances)
+	}
+
+	// held tidak boleh melebihi amount
+	err = db.Exec("UPDATE wallet_balances SET held = amount + 1 WHERE wallet_user_id = ?", "user-1").Error
+	if err == nil {
+		t.Error("held above amount was accepted")
+	}
+
+	// migrasi yang sudah jalan tidak dijalankan lagi
+	applied, err := migrator.Up()
+	if err != nil || len(applied) != 0 {
+		t.Fatalf("second up applied %d migrations: %v", len(applied), err)
+	}
+}
+
+func TestDownRestoresLegacyBalances(t *testing.T) {
+	db := testdb.OpenEmpty(t)
+
+	if err := db.AutoMigrate(&legacyWallet{}); err != nil {
+		t.Fatalf("create legacy schema: %v", err)
+	}
+	if err := db.Create(&legacyWallet{WalletUserID: "user-1", Balances: datatypes.JSON(`{"Coins": 1}`)}).Error; err != nil {
+		t.Fatalf("insert legacy wallet: %v", err)
+	}
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if err := db.Exec("UPDATE wallet_balances SET amount = 7 WHERE wallet_user_id = ?", "user-1").Error; err != nil {
+		t.Fatalf("update balance: %v", err)
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if _, err := migrator.Down(len(statuses) - 1); err != nil {
+		t.Fatalf("down: %v", err)
+	}
+
+	var raw string
+	if err := db.Raw("SELECT balances::text FROM wallets WHERE wallet_user_id = ?", "user-1").Scan(&raw).Error; err != nil {
+		t.Fatalf("read legacy balances: %v", err)
+	}
+	var restored map[string]decimal.Decimal
+	if err := json.Unmarshal([]byte(raw), &restored); err != nil {
+		t.Fatalf("parse legacy balances %q: %v", raw, err)
+	}
+	if !restored["Coins"].Equal(decimal.NewFromInt(7)) {
+		t.Errorf("legacy balances = %s, want Coins 7", raw)
+	}
+
+	pending, err := migrator.Pending()
+	if err != nil || pending != len(statuses)-1 {
+		t.Errorf("pending = %d, want %d (%v)", pending, len(statuses)-1, err)
+	}
+}
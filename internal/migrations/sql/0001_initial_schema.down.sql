@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS outbox;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
+DROP TABLE IF EXISTS api_clients;
+DROP TABLE IF EXISTS batch_items;
+DROP TABLE IF EXISTS batches;
+DROP TABLE IF EXISTS wallet_status_changes;
+DROP TABLE IF EXISTS balance_types;
+DROP TABLE IF EXISTS holds;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS wallet_balances;
+DROP TABLE IF EXISTS wallets;
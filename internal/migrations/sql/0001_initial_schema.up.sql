@@ -0,0 +1,227 @@
+-- Schema as created by AutoMigrate before versioned migrations existed.
+-- Databases created by the original AutoMigrate only have wallets with
+-- wallet_user_id, balances and the timestamps: CREATE TABLE IF NOT EXISTS
+-- leaves that table alone, so the columns added since are added below
+-- before anything refers to them.
+
+CREATE TABLE IF NOT EXISTS wallets (
+    id uuid PRIMARY KEY,
+    wallet_user_id text NOT NULL,
+    owner_id text,
+    kind text NOT NULL DEFAULT 'customer',
+    status text NOT NULL DEFAULT 'active',
+    metadata jsonb,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    CONSTRAINT uni_wallets_wallet_user_id UNIQUE (wallet_user_id)
+);
+ALTER TABLE wallets
+    ADD COLUMN IF NOT EXISTS id uuid,
+    ADD COLUMN IF NOT EXISTS owner_id text,
+    ADD COLUMN IF NOT EXISTS kind text NOT NULL DEFAULT 'customer',
+    ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'active',
+    ADD COLUMN IF NOT EXISTS metadata jsonb;
+UPDATE wallets SET id = gen_random_uuid() WHERE id IS NULL;
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conrelid = 'wallets'::regclass AND contype = 'p'
+    ) THEN
+        ALTER TABLE wallets ADD CONSTRAINT wallets_pkey PRIMARY KEY (id);
+    END IF;
+END $$;
+CREATE INDEX IF NOT EXISTS idx_wallets_wallet_user_id ON wallets (wallet_user_id);
+CREATE INDEX IF NOT EXISTS idx_wallets_owner_id ON wallets (owner_id);
+CREATE INDEX IF NOT EXISTS idx_wallets_status ON wallets (status);
+CREATE INDEX IF NOT EXISTS idx_wallets_deleted_at ON wallets (deleted_at);
+
+CREATE TABLE IF NOT EXISTS wallet_balances (
+    wallet_user_id text NOT NULL,
+    balance_type text NOT NULL,
+    amount numeric(38,18) NOT NULL DEFAULT 0,
+    held numeric(38,18) NOT NULL DEFAULT 0,
+    version bigint NOT NULL DEFAULT 0,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (wallet_user_id, balance_type),
+    CONSTRAINT chk_wallet_balances_amount_non_negative CHECK (amount >= 0),
+    CONSTRAINT chk_wallet_balances_held_within_amount CHECK (held >= 0 AND held <= amount)
+);
+ALTER TABLE wallet_balances
+    ADD COLUMN IF NOT EXISTS held numeric(38,18) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 0;
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conrelid = 'wallet_balances'::regclass AND conname = 'chk_wallet_balances_amount_non_negative'
+    ) THEN
+        ALTER TABLE wallet_balances ADD CONSTRAINT chk_wallet_balances_amount_non_negative CHECK (amount >= 0);
+    END IF;
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conrelid = 'wallet_balances'::regclass AND conname = 'chk_wallet_balances_held_within_amount'
+    ) THEN
+        ALTER TABLE wallet_balances ADD CONSTRAINT chk_wallet_balances_held_within_amount CHECK (held >= 0 AND held <= amount);
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id uuid PRIMARY KEY,
+    wallet_user_id text NOT NULL,
+    balance_type text NOT NULL,
+    amount numeric(38,18) NOT NULL,
+    balance_after numeric(38,18) NOT NULL,
+    reference text,
+    metadata jsonb,
+    reversal_of uuid,
+    hold_id uuid,
+    transfer_id uuid,
+    idempotency_key text,
+    request_hash text,
+    created_at timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_transactions_wallet_user_id ON transactions (wallet_user_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_wallet_created ON transactions (wallet_user_id, created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_wallet_idempotency_key ON transactions (wallet_user_id, idempotency_key);
+CREATE INDEX IF NOT EXISTS idx_transactions_balance_type ON transactions (balance_type);
+CREATE INDEX IF NOT EXISTS idx_transactions_reference ON transactions (reference);
+CREATE INDEX IF NOT EXISTS idx_transactions_reversal_of ON transactions (reversal_of);
+CREATE INDEX IF NOT EXISTS idx_transactions_hold_id ON transactions (hold_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_transfer_id ON transactions (transfer_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_created_at ON transactions (created_at);
+
+CREATE TABLE IF NOT EXISTS holds (
+    id uuid PRIMARY KEY,
+    wallet_user_id text NOT NULL,
+    balance_type text NOT NULL,
+    amount numeric(38,18) NOT NULL,
+    captured_amount numeric(38,18) NOT NULL DEFAULT 0,
+    status text NOT NULL,
+    reference text,
+    metadata jsonb,
+    expires_at timestamptz NOT NULL,
+    capture_transaction_id uuid,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_holds_wallet_user_id ON holds (wallet_user_id);
+CREATE INDEX IF NOT EXISTS idx_holds_status ON holds (status);
+CREATE INDEX IF NOT EXISTS idx_holds_reference ON holds (reference);
+CREATE INDEX IF NOT EXISTS idx_holds_expires_at ON holds (expires_at);
+
+CREATE TABLE IF NOT EXISTS balance_types (
+    name text PRIMARY KEY,
+    scale integer NOT NULL,
+    min_per_transaction numeric(38,18),
+    max_per_transaction numeric(38,18),
+    transferable boolean NOT NULL,
+    withdrawable boolean NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS wallet_status_changes (
+    id uuid PRIMARY KEY,
+    wallet_user_id text NOT NULL,
+    from_status text NOT NULL,
+    to_status text NOT NULL,
+    reason text NOT NULL,
+    sweep_transfer_id uuid,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_wallet_status_changes_wallet_created ON wallet_status_changes (wallet_user_id, created_at);
+
+CREATE TABLE IF NOT EXISTS batches (
+    id uuid PRIMARY KEY,
+    reference varchar(255),
+    status text NOT NULL,
+    total_rows bigint,
+    succeeded_rows bigint,
+    failed_rows bigint,
+    idempotency_key varchar(255),
+    request_hash varchar(64),
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_batches_status ON batches (status);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_batches_idempotency_key ON batches (idempotency_key);
+
+CREATE TABLE IF NOT EXISTS batch_items (
+    id uuid PRIMARY KEY,
+    batch_id uuid NOT NULL,
+    row_number bigint NOT NULL,
+    wallet_user_id text,
+    balance_type text,
+    amount text,
+    status text NOT NULL,
+    error_code text,
+    error_message text,
+    transaction_id uuid,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_batch_items_batch_row ON batch_items (batch_id, row_number);
+CREATE INDEX IF NOT EXISTS idx_batch_items_status ON batch_items (status);
+
+CREATE TABLE IF NOT EXISTS api_clients (
+    id uuid PRIMARY KEY,
+    name varchar(255) NOT NULL,
+    api_key varchar(64) NOT NULL,
+    secret_hash varchar(64) NOT NULL,
+    scopes text NOT NULL,
+    disabled boolean NOT NULL DEFAULT false,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_clients_api_key ON api_clients (api_key);
+CREATE INDEX IF NOT EXISTS idx_api_clients_deleted_at ON api_clients (deleted_at);
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id uuid PRIMARY KEY,
+    url text NOT NULL,
+    event_types text NOT NULL,
+    secret varchar(128) NOT NULL,
+    active boolean NOT NULL DEFAULT true,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_deleted_at ON webhook_subscriptions (deleted_at);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id uuid PRIMARY KEY,
+    subscription_id uuid NOT NULL,
+    event_id uuid NOT NULL,
+    event_type text NOT NULL,
+    payload jsonb NOT NULL,
+    status text NOT NULL,
+    attempts bigint NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz,
+    last_status_code bigint,
+    last_error text,
+    delivered_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription_event ON webhook_deliveries (subscription_id, event_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_event_id ON webhook_deliveries (event_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS outbox (
+    id bigserial PRIMARY KEY,
+    event_id uuid NOT NULL,
+    type text NOT NULL,
+    wallet_user_id text NOT NULL,
+    payload jsonb NOT NULL,
+    attempts bigint NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz,
+    last_error text,
+    published_at timestamptz,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_event_id ON outbox (event_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_wallet_user_id ON outbox (wallet_user_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_published_at ON outbox (published_at);
@@ -0,0 +1,19 @@
+-- Write the current balances back into the legacy wallets.balances JSON
+-- column, so a build that still reads it sees the balances as they are now
+-- and not as they were when the backfill ran. Owner IDs are kept.
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'wallets' AND column_name = 'balances'
+    ) THEN
+        UPDATE wallets w
+        SET balances = b.balances
+        FROM (
+            SELECT wallet_user_id, jsonb_object_agg(balance_type, amount) AS balances
+            FROM wallet_balances
+            GROUP BY wallet_user_id
+        ) b
+        WHERE b.wallet_user_id = w.wallet_user_id;
+    END IF;
+END $$;
@@ -0,0 +1,22 @@
+-- Copy balances from the legacy wallets.balances JSON column into
+-- wallet_balances. Rows that already exist are left untouched and the JSON
+-- column itself is kept as-is.
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'wallets' AND column_name = 'balances'
+    ) THEN
+        INSERT INTO wallet_balances (wallet_user_id, balance_type, amount, version, created_at, updated_at)
+        SELECT w.wallet_user_id, b.key, (b.value #>> '{}')::numeric, 0, NOW(), NOW()
+        FROM wallets w, jsonb_each(w.balances::jsonb) AS b
+        WHERE w.balances IS NOT NULL
+            AND jsonb_typeof(w.balances::jsonb) = 'object'
+        ON CONFLICT (wallet_user_id, balance_type) DO NOTHING;
+    END IF;
+END $$;
+
+-- Wallets created before owners existed are owned by their wallet user ID
+UPDATE wallets
+SET owner_id = wallet_user_id
+WHERE owner_id IS NULL OR owner_id = '';
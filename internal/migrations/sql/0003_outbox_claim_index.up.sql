@@ -0,0 +1,5 @@
+-- The dispatcher claims due events by wallet and ID among the unpublished
+-- ones. Events written before next_attempt_at was always set are due now.
+UPDATE outbox SET next_attempt_at = COALESCE(created_at, NOW()) WHERE next_attempt_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_unpublished ON outbox (wallet_user_id, id) WHERE published_at IS NULL;
@@ -0,0 +1,8 @@
+-- Former balance type names and the name they were renamed to. Ledger
+-- entries keep the name they were recorded with.
+CREATE TABLE IF NOT EXISTS balance_type_aliases (
+    old_name text PRIMARY KEY,
+    new_name text NOT NULL,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_balance_type_aliases_new_name ON balance_type_aliases (new_name);
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// API scopes
+const (
+	ScopeWalletRead   = "wallet:read"
+	ScopeWalletCreate = "wallet:create"
+	ScopeWalletCredit = "wallet:credit"
+	ScopeWalletDebit  = "wallet:debit"
+	ScopeWalletAdmin  = "wallet:admin"
+)
+
+// AllScopes lists every scope an API client can be granted
+var AllScopes = []string{ScopeWalletRead, ScopeWalletCreate, ScopeWalletCredit, ScopeWalletDebit, ScopeWalletAdmin}
+
+// APIClient is a system allowed to call the wallet API. It authenticates
+// either with "Authorization: token <api_key>:<api_secret>" or with a JWT
+// whose subject is the client ID. Only a SHA-256 hash of the secret is
+// stored. Scopes is a space-separated list.
+type APIClient struct {
+	ID         string         `json:"id" gorm:"type:uuid;primaryKey"`
+	Name       string         `json:"name" gorm:"size:255;not null"`
+	APIKey     string         `json:"api_key" gorm:"size:64;not null;uniqueIndex"`
+	SecretHash string         `json:"-" gorm:"size:64;not null"`
+	Scopes     string         `json:"scopes" gorm:"type:text;not null"`
+	Disabled   bool           `json:"disabled" gorm:"not null;default:false"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableName specifies the table name for the APIClient model
+func (APIClient) TableName() string {
+	return "api_clients"
+}
+
+// BeforeCreate assigns a new ID to the API client
+func (c *APIClient) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// ScopeList returns the client's scopes
+func (c *APIClient) ScopeList() []string {
+	return strings.Fields(c.Scopes)
+}
+
+// HasScope reports whether the client was granted scope
+func (c *APIClient) HasScope(scope string) bool {
+	for _, s := range c.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BalanceType describes a kind of balance a wallet can hold (e.g. Coins, Exp)
+// and the rules for moving it
+type BalanceType struct {
+	Name string `json:"name" gorm:"primaryKey"`
+
+	// Scale is the number of decimal places allowed in amounts
+	Scale int32 `json:"scale" gorm:"not null"`
+
+	// MinPerTransaction and MaxPerTransaction bound a single operation;
+	// a null value means no limit
+	MinPerTransaction decimal.NullDecimal `json:"min_per_transaction" gorm:"type:numeric(38,18)"`
+	MaxPerTransaction decimal.NullDecimal `json:"max_per_transaction" gorm:"type:numeric(38,18)"`
+
+	Transferable bool      `json:"transferable" gorm:"not null"`
+	Withdrawable bool      `json:"withdrawable" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for the BalanceType model
+func (BalanceType) TableName() string {
+	return "balance_types"
+}
+
+// BalanceTypeAlias maps a former balance type name to the current one.
+// Ledger entries are never rewritten, so entries recorded before a rename
+// keep the old name and are resolved through this table when read.
+type BalanceTypeAlias struct {
+	OldName   string    `json:"old_name" gorm:"primaryKey"`
+	NewName   string    `json:"new_name" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for the BalanceTypeAlias model
+func (BalanceTypeAlias) TableName() string {
+	return "balance_type_aliases"
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Batch statuses
+const (
+	BatchStatusPending             = "pending"
+	BatchStatusProcessing          = "processing"
+	BatchStatusCompleted           = "completed"
+	BatchStatusCompletedWithErrors = "completed_with_errors"
+)
+
+// Batch item statuses
+const (
+	BatchItemStatusPending   = "pending"
+	BatchItemStatusSucceeded = "succeeded"
+	BatchItemStatusFailed    = "failed"
+)
+
+// Batch is a bulk credit job, e.g. campaign rewards for many wallets. Its
+// rows are credited in chunks in the background; the counters are refreshed
+// after every chunk so callers can poll progress. RequestHash fingerprints
+// the submitted rows so a resubmission under the same IdempotencyKey can be
+// told apart from a conflicting one.
+type Batch struct {
+	ID             string    `json:"id" gorm:"type:uuid;primaryKey"`
+	Reference      string    `json:"reference,omitempty" gorm:"size:255"`
+	Status         string    `json:"status" gorm:"not null;index"`
+	TotalRows      int       `json:"total_rows"`
+	SucceededRows  int       `json:"succeeded_rows"`
+	FailedRows     int       `json:"failed_rows"`
+	IdempotencyKey *string   `json:"idempotency_key,omitempty" gorm:"size:255;uniqueIndex"`
+	RequestHash    string    `json:"-" gorm:"size:64"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for the Batch model
+func (Batch) TableName() string {
+	return "batches"
+}
+
+// BeforeCreate assigns a new ID to the batch
+func (b *Batch) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == "" {
+		b.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BatchResult is returned when a batch is submitted
+type BatchResult struct {
+	*Batch
+
+	// Replayed is set when the batch was submitted before under the same idempotency key
+	Replayed bool `json:"-"`
+}
+
+// BatchItem is one row of a batch. Amount keeps the submitted value as-is;
+// it is validated when the row is credited.
+type BatchItem struct {
+	ID            string    `json:"-" gorm:"type:uuid;primaryKey"`
+	BatchID       string    `json:"batch_id" gorm:"type:uuid;not null;uniqueIndex:idx_batch_items_batch_row,priority:1"`
+	RowNumber     int       `json:"row" gorm:"not null;uniqueIndex:idx_batch_items_batch_row,priority:2"`
+	WalletUserID  string    `json:"wallet_user_id"`
+	BalanceType   string    `json:"type"`
+	Amount        string    `json:"amount"`
+	Status        string    `json:"status" gorm:"not null;index"`
+	ErrorCode     string    `json:"error_code,omitempty"`
+	ErrorMessage  string    `json:"error_message,omitempty"`
+	TransactionID *string   `json:"transaction_id,omitempty" gorm:"type:uuid"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for the BatchItem model
+func (BatchItem) TableName() string {
+	return "batch_items"
+}
+
+// BeforeCreate assigns a new ID to the batch item
+func (i *BatchItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BatchItemPage is one page of batch items, ordered by row number
+type BatchItemPage struct {
+	Items        []BatchItem `json:"items"`
+	NextAfterRow int         `json:"next_after_row,omitempty"`
+	HasMore      bool        `json:"has_more"`
+}
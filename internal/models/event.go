@@ -0,0 +1,25 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event types
+const (
+	EventWalletCreated   = "wallet.created"
+	EventBalanceCredited = "balance.credited"
+	EventBalanceDebited  = "balance.debited"
+)
+
+// EventTypes lists every event type subscribers can receive
+var EventTypes = []string{EventWalletCreated, EventBalanceCredited, EventBalanceDebited}
+
+// Event is something that happened to a wallet, as delivered to subscribers
+type Event struct {
+	ID           string          `json:"id"`
+	Type         string          `json:"type"`
+	WalletUserID string          `json:"wallet_user_id"`
+	Data         json.RawMessage `json:"data"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Hold statuses
+const (
+	HoldStatusActive   = "active"
+	HoldStatusCaptured = "captured"
+	HoldStatusReleased = "released"
+	HoldStatusExpired  = "expired"
+)
+
+// Hold reserves part of a wallet balance until it is captured, released or
+// expires. An active hold reduces the available balance but not the total.
+type Hold struct {
+	ID                   string          `json:"id" gorm:"type:uuid;primaryKey"`
+	WalletUserID         string          `json:"wallet_user_id" gorm:"not null;index"`
+	BalanceType          string          `json:"type" gorm:"not null"`
+	Amount               decimal.Decimal `json:"amount" gorm:"type:numeric(38,18);not null"`
+	CapturedAmount       decimal.Decimal `json:"captured_amount" gorm:"type:numeric(38,18);not null;default:0"`
+	Status               string          `json:"status" gorm:"not null;index"`
+	Reference            string          `json:"reference,omitempty" gorm:"index"`
+	Metadata             datatypes.JSON  `json:"metadata,omitempty"`
+	ExpiresAt            time.Time       `json:"expires_at" gorm:"not null;index"`
+	CaptureTransactionID *string         `json:"capture_transaction_id,omitempty" gorm:"type:uuid"`
+	CreatedAt            time.Time       `json:"created_at"`
+	UpdatedAt            time.Time       `json:"updated_at"`
+}
+
+// TableName specifies the table name for the Hold model
+func (Hold) TableName() string {
+	return "holds"
+}
+
+// BeforeCreate assigns a new ID to the hold
+func (h *Hold) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == "" {
+		h.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// HoldCaptureResult is returned when a hold is captured
+type HoldCaptureResult struct {
+	Hold        *Hold        `json:"hold"`
+	Transaction *Transaction `json:"transaction"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// OutboxEvent is an event waiting to be published, written in the same
+// database transaction as the change it describes. The sequential ID gives
+// the publishing order. PublishedAt is set once every sink accepted it.
+type OutboxEvent struct {
+	ID            int64          `json:"id" gorm:"primaryKey;autoIncrement;index:idx_outbox_unpublished,priority:2,where:published_at IS NULL"`
+	EventID       string         `json:"event_id" gorm:"type:uuid;not null;uniqueIndex"`
+	Type          string         `json:"type" gorm:"not null"`
+	WalletUserID  string         `json:"wallet_user_id" gorm:"not null;index;index:idx_outbox_unpublished,priority:1"`
+	Payload       datatypes.JSON `json:"payload" gorm:"not null"`
+	Attempts      int            `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt time.Time      `json:"next_attempt_at"`
+	LastError     string         `json:"last_error,omitempty" gorm:"type:text"`
+	PublishedAt   *time.Time     `json:"published_at,omitempty" gorm:"index"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// TableName specifies the table name for the OutboxEvent model
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
+
+// Event decodes the stored event
+func (o *OutboxEvent) Event() (*Event, error) {
+	var event Event
+	if err := json.Unmarshal(o.Payload, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
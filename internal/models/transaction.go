@@ -0,0 +1,92 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ErrImmutableTransaction is returned when something tries to modify a ledger entry
+var ErrImmutableTransaction = errors.New("ledger transactions are immutable")
+
+// Transaction is an append-only ledger entry for a single balance movement.
+// Credits have a positive Amount, debits a negative one. A refund is a credit
+// whose ReversalOf points at the debit it compensates. RequestHash
+// fingerprints the request written under IdempotencyKey so that reusing the
+// key with a different body can be detected.
+type Transaction struct {
+	ID             string          `json:"id" gorm:"type:uuid;primaryKey"`
+	WalletUserID   string          `json:"wallet_user_id" gorm:"not null;index;index:idx_transactions_wallet_created,priority:1;uniqueIndex:idx_transactions_wallet_idempotency_key,priority:1"`
+	BalanceType    string          `json:"type" gorm:"not null;index"`
+	Amount         decimal.Decimal `json:"amount" gorm:"type:numeric(38,18);not null"`
+	BalanceAfter   decimal.Decimal `json:"balance_after" gorm:"type:numeric(38,18);not null"`
+	Reference      string          `json:"reference,omitempty" gorm:"index"`
+	Metadata       datatypes.JSON  `json:"metadata,omitempty"`
+	ReversalOf     *string         `json:"reversal_of,omitempty" gorm:"type:uuid;index"`
+	HoldID         *string         `json:"hold_id,omitempty" gorm:"type:uuid;index"`
+	TransferID     *string         `json:"transfer_id,omitempty" gorm:"type:uuid;index"`
+	IdempotencyKey *string         `json:"idempotency_key,omitempty" gorm:"uniqueIndex:idx_transactions_wallet_idempotency_key,priority:2"`
+	RequestHash    string          `json:"-"`
+	CreatedAt      time.Time       `json:"created_at" gorm:"not null;index;index:idx_transactions_wallet_created,priority:2"`
+}
+
+// Transaction directions used for filtering
+const (
+	DirectionCredit = "credit"
+	DirectionDebit  = "debit"
+)
+
+// TransactionPage is a single page of ledger entries
+type TransactionPage struct {
+	Transactions []Transaction `json:"transactions"`
+	NextCursor   string        `json:"next_cursor,omitempty"`
+	HasMore      bool          `json:"has_more"`
+}
+
+// BalanceUpdateResult is returned by credits and debits: the wallet after the
+// change plus the ledger entry that recorded it
+type BalanceUpdateResult struct {
+	*Wallet
+	Transaction *Transaction `json:"transaction"`
+
+	// Replayed is set when an idempotency key matched an earlier request and
+	// no new change was applied
+	Replayed bool `json:"-"`
+}
+
+// TransferResult holds both legs of a wallet-to-wallet transfer
+type TransferResult struct {
+	TransferID string       `json:"transfer_id"`
+	Debit      *Transaction `json:"debit"`
+	Credit     *Transaction `json:"credit"`
+
+	// Replayed is set when an idempotency key matched an earlier transfer
+	Replayed bool `json:"-"`
+}
+
+// TableName specifies the table name for the Transaction model
+func (Transaction) TableName() string {
+	return "transactions"
+}
+
+// BeforeCreate assigns a new ID to the ledger entry
+func (t *Transaction) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeUpdate rejects any update to an existing ledger entry
+func (t *Transaction) BeforeUpdate(tx *gorm.DB) error {
+	return ErrImmutableTransaction
+}
+
+// BeforeDelete rejects deleting a ledger entry
+func (t *Transaction) BeforeDelete(tx *gorm.DB) error {
+	return ErrImmutableTransaction
+}
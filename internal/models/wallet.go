@@ -1,16 +1,45 @@
 package models
 
 import (
-	"encoding/json"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
+// Wallet kinds
+const (
+	WalletKindCustomer = "customer"
+	WalletKindSeller   = "seller"
+	WalletKindPlatform = "platform"
+	WalletKindEscrow   = "escrow"
+)
+
+// Wallet statuses. Frozen wallets reject all balance movements,
+// debit-blocked wallets only reject debits and closed wallets are final.
+const (
+	WalletStatusActive       = "active"
+	WalletStatusFrozen       = "frozen"
+	WalletStatusDebitBlocked = "debit_blocked"
+	WalletStatusClosed       = "closed"
+)
+
+// Wallet holds the balances of one owner. A user can own several wallets,
+// e.g. a seller's earnings and promotional wallets. WalletUserID stays the
+// unique handle that balances, ledger entries and holds refer to; ID is the
+// wallet's own identifier and OwnerID the user it belongs to.
 type Wallet struct {
+	ID           string         `json:"id" gorm:"type:uuid;primaryKey"`
 	WalletUserID string         `json:"wallet_user_id" gorm:"unique;not null;index"`
-	Balances     datatypes.JSON `json:"balances"`
+	OwnerID      string         `json:"owner_id" gorm:"index"`
+	Kind         string         `json:"kind" gorm:"not null;default:customer"`
+	Status       string         `json:"status" gorm:"not null;default:active;index"`
+	Balances     BalanceData    `json:"balances" gorm:"-"`
+	Available    BalanceData    `json:"available" gorm:"-"`
+	Held         BalanceData    `json:"held" gorm:"-"`
+	Metadata     datatypes.JSON `json:"metadata,omitempty"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
@@ -21,28 +50,46 @@ func (Wallet) TableName() string {
 	return "wallets"
 }
 
-// BalanceData represents the structure of the balances JSON field
-type BalanceData map[string]float64
+// BeforeCreate assigns a new ID to the wallet
+func (w *Wallet) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+	return nil
+}
 
+// CanCredit reports whether the wallet accepts incoming balance
+func (w *Wallet) CanCredit() bool {
+	return w.Status == WalletStatusActive || w.Status == WalletStatusDebitBlocked
+}
 
-// GetBalances parses the JSON balances field into BalanceData
-func (w *Wallet) GetBalances() (*BalanceData, error) {
-	var balances BalanceData
-	if len(w.Balances) == 0 {
-		return &balances, nil
-	}
-	if err := json.Unmarshal(w.Balances, &balances); err != nil {
-		return nil, err
+// CanDebit reports whether balance may leave or be reserved on the wallet
+func (w *Wallet) CanDebit() bool {
+	return w.Status == WalletStatusActive
+}
+
+// BalanceData maps a balance type to its amount. It is built from the
+// wallet_balances rows and keeps the original `balances` JSON shape.
+// Amounts are exact decimals and serialize as JSON strings.
+type BalanceData map[string]decimal.Decimal
+
+// SetBalanceRows fills Balances, Available and Held from the wallet's
+// per-type balance rows
+func (w *Wallet) SetBalanceRows(rows []WalletBalance) {
+	w.Balances = make(BalanceData, len(rows))
+	w.Available = make(BalanceData, len(rows))
+	w.Held = make(BalanceData, len(rows))
+	for _, row := range rows {
+		w.SetBalanceRow(row)
 	}
-	return &balances, nil
 }
 
-// SetBalances sets the balances field from BalanceData
-func (w *Wallet) SetBalances(balances *BalanceData) error {
-	data, err := json.Marshal(balances)
-	if err != nil {
-		return err
+// SetBalanceRow updates a single balance type from its balance row
+func (w *Wallet) SetBalanceRow(row WalletBalance) {
+	if w.Balances == nil {
+		w.SetBalanceRows(nil)
 	}
-	w.Balances = data
-	return nil
+	w.Balances[row.BalanceType] = row.Amount
+	w.Available[row.BalanceType] = row.Available()
+	w.Held[row.BalanceType] = row.Held
 }
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// WalletBalance holds the amount of one balance type in a wallet. Held is the
+// part of Amount reserved by active holds; Amount - Held is available to spend.
+// Version is incremented on every change to the row.
+type WalletBalance struct {
+	WalletUserID string          `json:"wallet_user_id" gorm:"primaryKey"`
+	BalanceType  string          `json:"type" gorm:"primaryKey"`
+	Amount       decimal.Decimal `json:"amount" gorm:"type:numeric(38,18);not null;default:0;check:chk_wallet_balances_amount_non_negative,amount >= 0"`
+	Held         decimal.Decimal `json:"held" gorm:"type:numeric(38,18);not null;default:0;check:chk_wallet_balances_held_within_amount,held >= 0 AND held <= amount"`
+	Version      int64           `json:"version" gorm:"not null;default:0"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+// Available returns the part of the balance that is not reserved by holds
+func (b WalletBalance) Available() decimal.Decimal {
+	return b.Amount.Sub(b.Held)
+}
+
+// TableName specifies the table name for the WalletBalance model
+func (WalletBalance) TableName() string {
+	return "wallet_balances"
+}
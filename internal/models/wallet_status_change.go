@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WalletStatusChange records who changed a wallet's status and why. When a
+// wallet is closed with a sweep, SweepTransferID links the transfer legs that
+// moved its remaining balances.
+type WalletStatusChange struct {
+	ID              string    `json:"id" gorm:"type:uuid;primaryKey"`
+	WalletUserID    string    `json:"wallet_user_id" gorm:"not null;index:idx_wallet_status_changes_wallet_created,priority:1"`
+	FromStatus      string    `json:"from_status" gorm:"not null"`
+	ToStatus        string    `json:"to_status" gorm:"not null"`
+	Reason          string    `json:"reason" gorm:"type:text;not null"`
+	SweepTransferID *string   `json:"sweep_transfer_id,omitempty" gorm:"type:uuid"`
+	CreatedAt       time.Time `json:"created_at" gorm:"index:idx_wallet_status_changes_wallet_created,priority:2"`
+}
+
+// TableName specifies the table name for the WalletStatusChange model
+func (WalletStatusChange) TableName() string {
+	return "wallet_status_changes"
+}
+
+// BeforeCreate assigns a new ID to the status change
+func (c *WalletStatusChange) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
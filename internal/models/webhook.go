@@ -0,0 +1,86 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Webhook delivery statuses. Failed deliveries are retried with exponential
+// backoff while pending; after the last attempt they are dead.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookSubscription is an endpoint that receives signed events. EventTypes
+// is a space-separated list. Secret signs the payloads and is never returned
+// after creation.
+type WebhookSubscription struct {
+	ID         string         `json:"id" gorm:"type:uuid;primaryKey"`
+	URL        string         `json:"url" gorm:"type:text;not null"`
+	EventTypes string         `json:"event_types" gorm:"type:text;not null"`
+	Secret     string         `json:"-" gorm:"size:128;not null"`
+	Active     bool           `json:"active" gorm:"not null;default:true"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableName specifies the table name for the WebhookSubscription model
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// BeforeCreate assigns a new ID to the subscription
+func (w *WebhookSubscription) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Subscribes reports whether the subscription wants events of eventType
+func (w *WebhookSubscription) Subscribes(eventType string) bool {
+	for _, t := range strings.Fields(w.EventTypes) {
+		if t == eventType || t == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event queued for one subscription. Payload is the
+// exact JSON body sent on every attempt.
+type WebhookDelivery struct {
+	ID             string         `json:"id" gorm:"type:uuid;primaryKey"`
+	SubscriptionID string         `json:"subscription_id" gorm:"type:uuid;not null;uniqueIndex:idx_webhook_deliveries_subscription_event,priority:1"`
+	EventID        string         `json:"event_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_webhook_deliveries_subscription_event,priority:2"`
+	EventType      string         `json:"event_type" gorm:"not null"`
+	Payload        datatypes.JSON `json:"payload" gorm:"not null"`
+	Status         string         `json:"status" gorm:"not null;index:idx_webhook_deliveries_due,priority:1"`
+	Attempts       int            `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  time.Time      `json:"next_attempt_at" gorm:"index:idx_webhook_deliveries_due,priority:2"`
+	LastStatusCode int            `json:"last_status_code,omitempty"`
+	LastError      string         `json:"last_error,omitempty" gorm:"type:text"`
+	DeliveredAt    *time.Time     `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// TableName specifies the table name for the WebhookDelivery model
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// BeforeCreate assigns a new ID to the delivery
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+type apiClientRepository struct {
+	db *gorm.DB
+}
+
+type APIClientRepository interface {
+	// Create creates a new API client
+	Create(client *models.APIClient) error
+
+	// GetByID retrieves an API client by its ID
+	GetByID(id string) (*models.APIClient, error)
+
+	// GetByAPIKey retrieves an API client by its API key
+	GetByAPIKey(apiKey string) (*models.APIClient, error)
+
+	// List returns all API clients ordered by name
+	List() ([]models.APIClient, error)
+
+	// Update saves changes to an existing API client
+	Update(client *models.APIClient) error
+
+	// Delete soft deletes an API client
+	Delete(id string) error
+}
+
+// NewAPIClientRepository creates a new API client repository
+func NewAPIClientRepository(db *gorm.DB) APIClientRepository {
+	return &apiClientRepository{db: db}
+}
+
+func (r *apiClientRepository) Create(client *models.APIClient) error {
+	if err := r.db.Create(client).Error; err != nil {
+		if isUniqueConstraintError(err) {
+			return utils.NewWalletError(utils.CodeAPIClientExists, "API client with this key already exists", err.Error())
+		}
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to create API client", err.Error())
+	}
+	return nil
+}
+
+func (r *apiClientRepository) GetByID(id string) (*models.APIClient, error) {
+	return r.find("id = ?", id)
+}
+
+func (r *apiClientRepository) GetByAPIKey(apiKey string) (*models.APIClient, error) {
+	return r.find("api_key = ?", apiKey)
+}
+
+func (r *apiClientRepository) find(query string, value string) (*models.APIClient, error) {
+	var client models.APIClient
+	if err := r.db.First(&client, query, value).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.NewWalletError(utils.CodeAPIClientNotFound, "API client not found", "")
+		}
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to retrieve API client", err.Error())
+	}
+	return &client, nil
+}
+
+func (r *apiClientRepository) List() ([]models.APIClient, error) {
+	var clients []models.APIClient
+	if err := r.db.Order("name").Find(&clients).Error; err != nil {
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to list API clients", err.Error())
+	}
+	return clients, nil
+}
+
+func (r *apiClientRepository) Update(client *models.APIClient) error {
+	if err := r.db.Save(client).Error; err != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to update API client", err.Error())
+	}
+	return nil
+}
+
+func (r *apiClientRepository) Delete(id string) error {
+	result := r.db.Delete(&models.APIClient{}, "id = ?", id)
+	if result.Error != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to delete API client", result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return utils.NewWalletError(utils.CodeAPIClientNotFound, "API client not found", "")
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+type balanceTypeRepository struct {
+	db *gorm.DB
+}
+
+type BalanceTypeRepository interface {
+	// Create creates a new balance type
+	Create(balanceType *models.BalanceType) error
+
+	// GetByName retrieves a balance type by name
+	GetByName(name string) (*models.BalanceType, error)
+
+	// List returns all balance types ordered by name
+	List() ([]models.BalanceType, error)
+
+	// Update saves changes to an existing balance type
+	Update(balanceType *models.BalanceType) error
+
+	// Delete removes a balance type by name
+	Delete(name string) error
+}
+
+// NewBalanceTypeRepository creates a new balance type repository
+func NewBalanceTypeRepository(db *gorm.DB) BalanceTypeRepository {
+	return &balanceTypeRepository{db: db}
+}
+
+func (r *balanceTypeRepository) Create(balanceType *models.BalanceType) error {
+	if err := r.db.Create(balanceType).Error; err != nil {
+		if isUniqueConstraintError(err) {
+			return utils.NewWalletError(utils.CodeBalanceTypeExists, "Balance type already exists", err.Error())
+		}
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to create balance type", err.Error())
+	}
+	return nil
+}
+
+func (r *balanceTypeRepository) GetByName(name string) (*models.BalanceType, error) {
+	var balanceType models.BalanceType
+	if err := r.db.First(&balanceType, "name = ?", name).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.NewWalletError(utils.CodeBalanceTypeNotFound, "Balance type not found", "")
+		}
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to retrieve balance type", err.Error())
+	}
+	return &balanceType, nil
+}
+
+func (r *balanceTypeRepository) List() ([]models.BalanceType, error) {
+	var balanceTypes []models.BalanceType
+	if err := r.db.Order("name").Find(&balanceTypes).Error; err != nil {
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to list balance types", err.Error())
+	}
+	return balanceTypes, nil
+}
+
+func (r *balanceTypeRepository) Update(balanceType *models.BalanceType) error {
+	if err := r.db.Save(balanceType).Error; err != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to update balance type", err.Error())
+	}
+	return nil
+}
+
+func (r *balanceTypeRepository) Delete(name string) error {
+	result := r.db.Delete(&models.BalanceType{}, "name = ?", name)
+	if result.Error != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to delete balance type", result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return utils.NewWalletError(utils.CodeBalanceTypeNotFound, "Balance type not found", "")
+	}
+	return nil
+}
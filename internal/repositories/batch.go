@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+// batchItemInsertSize limits how many batch items are inserted per statement
+const batchItemInsertSize = 1000
+
+type batchRepository struct {
+	db *gorm.DB
+}
+
+type BatchRepository interface {
+	// WithTx returns a repository bound to the given database transaction
+	WithTx(tx *gorm.DB) BatchRepository
+
+	// Create creates a batch together with its items
+	Create(batch *models.Batch, items []models.BatchItem) error
+
+	// GetByID retrieves a batch by its ID
+	GetByID(id string) (*models.Batch, error)
+
+	// FindByIdempotencyKey returns the batch submitted under key, or nil if there is none
+	FindByIdempotencyKey(key string) (*models.Batch, error)
+
+	// Update saves changes to an existing batch
+	Update(batch *models.Batch) error
+
+	// RefreshCounts recalculates the succeeded and failed row counters of a batch
+	RefreshCounts(batch *models.Batch) error
+
+	// ResetFailedItems marks the failed items of a batch as pending again
+	ResetFailedItems(batchID string) error
+
+	// ListItems returns items of a batch after the given row number, ordered
+	// by row number and optionally filtered by status
+	ListItems(batchID, status string, afterRow, limit int) ([]models.BatchItem, error)
+
+	// UpdateItem saves the result of a batch item
+	UpdateItem(item *models.BatchItem) error
+}
+
+// NewBatchRepository creates a new batch repository
+func NewBatchRepository(db *gorm.DB) BatchRepository {
+	return &batchRepository{db: db}
+}
+
+func (r *batchRepository) WithTx(tx *gorm.DB) BatchRepository {
+	return &batchRepository{db: tx}
+}
+
+func (r *batchRepository) Create(batch *models.Batch, items []models.BatchItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			if isUniqueConstraintError(err) {
+				return utils.NewWalletError(utils.CodeIdempotencyConflict, "Idempotency key was already used for another batch", err.Error())
+			}
+			return utils.NewWalletError(utils.CodeDatabaseError, "Failed to create batch", err.Error())
+		}
+
+		for i := range items {
+			items[i].BatchID = batch.ID
+		}
+		if err := tx.CreateInBatches(items, batchItemInsertSize).Error; err != nil {
+			return utils.NewWalletError(utils.CodeDatabaseError, "Failed to create batch items", err.Error())
+		}
+		return nil
+	})
+}
+
+func (r *batchRepository) GetByID(id string) (*models.Batch, error) {
+	var batch models.Batch
+	if err := r.db.First(&batch, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.NewWalletError(utils.CodeBatchNotFound, "Batch not found", "")
+		}
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to retrieve batch", err.Error())
+	}
+	return &batch, nil
+}
+
+func (r *batchRepository) FindByIdempotencyKey(key string) (*models.Batch, error) {
+	var batch models.Batch
+	err := r.db.Where("idempotency_key = ?", key).Limit(1).Find(&batch).Error
+	if err != nil {
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to look up idempotency key", err.Error())
+	}
+	if batch.ID == "" {
+		return nil, nil
+	}
+	return &batch, nil
+}
+
+func (r *batchRepository) Update(batch *models.Batch) error {
+	if err := r.db.Save(batch).Error; err != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to update batch", err.Error())
+	}
+	return nil
+}
+
+func (r *batchRepository) RefreshCounts(batch *models.Batch) error {
+	var counts []struct {
+		Status string
+		Count  int
+	}
+	err := r.db.Model(&models.BatchItem{}).
+		Select("status, COUNT(*) AS count").
+		Where("batch_id = ?", batch.ID).
+		Group("status").
+		Scan(&counts).Error
+	if err != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to count batch items", err.Error())
+	}
+
+	batch.SucceededRows, batch.FailedRows = 0, 0
+	for _, c := range counts {
+		switch c.Status {
+		case models.BatchItemStatusSucceeded:
+			batch.SucceededRows = c.Count
+		case models.BatchItemStatusFailed:
+			batch.FailedRows = c.Count
+		}
+	}
+	return r.Update(batch)
+}
+
+func (r *batchRepository) ResetFailedItems(batchID string) error {
+	err := r.db.Model(&models.BatchItem{}).
+		Where("batch_id = ? AND status = ?", batchID, models.BatchItemStatusFailed).
+		Updates(map[string]interface{}{
+			"status":        models.BatchItemStatusPending,
+			"error_code":    "",
+			"error_message": "",
+		}).Error
+	if err != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to reset batch items", err.Error())
+	}
+	return nil
+}
+
+func (r *batchRepository) ListItems(batchID, status string, afterRow, limit int) ([]models.BatchItem, error) {
+	query := r.db.Where("batch_id = ? AND row_number > ?", batchID, afterRow)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var items []models.BatchItem
+	if err := query.Order("row_number").Limit(limit).Find(&items).Error; err != nil {
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to list batch items", err.Error())
+	}
+	return items, nil
+}
+
+func (r *batchRepository) UpdateItem(item *models.BatchItem) error {
+	if err := r.db.Save(item).Error; err != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to update batch item", err.Error())
+	}
+	return nil
+}
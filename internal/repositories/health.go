@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type healthRepository struct {
+	db *gorm.DB
+}
+
+type HealthRepository interface {
+	// Ping checks that a pooled database connection is usable
+	Ping(ctx context.Context) error
+}
+
+// NewHealthRepository creates a new health repository
+func NewHealthRepository(db *gorm.DB) HealthRepository {
+	return &healthRepository{db: db}
+}
+
+func (r *healthRepository) Ping(ctx context.Context) error {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"time"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/pkg/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type holdRepository struct {
+	db *gorm.DB
+}
+
+type HoldRepository interface {
+	// WithTx returns a repository bound to the given database transaction
+	WithTx(tx *gorm.DB) HoldRepository
+
+	// Create creates a new hold
+	Create(hold *models.Hold) error
+
+	// GetByID retrieves a hold by its ID
+	GetByID(id string) (*models.Hold, error)
+
+	// GetByIDForUpdate retrieves a hold and locks its row until the
+	// surrounding transaction ends
+	GetByIDForUpdate(id string) (*models.Hold, error)
+
+	// Update saves changes to an existing hold
+	Update(hold *models.Hold) error
+
+	// ListExpired returns active holds whose expiry time is before now
+	ListExpired(now time.Time, limit int) ([]models.Hold, error)
+}
+
+// NewHoldRepository creates a new hold repository
+func NewHoldRepository(db *gorm.DB) HoldRepository {
+	return &holdRepository{db: db}
+}
+
+func (r *holdRepository) WithTx(tx *gorm.DB) HoldRepository {
+	return &holdRepository{db: tx}
+}
+
+func (r *holdRepository) Create(hold *models.Hold) error {
+	if err := r.db.Create(hold).Error; err != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to create hold", err.Error())
+	}
+	return nil
+}
+
+func (r *holdRepository) GetByID(id string) (*models.Hold, error) {
+	return r.find(r.db, id)
+}
+
+func (r *holdRepository) GetByIDForUpdate(id string) (*models.Hold, error) {
+	return r.find(r.db.Clauses(clause.Locking{Strength: "UPDATE"}), id)
+}
+
+func (r *holdRepository) find(db *gorm.DB, id string) (*models.Hold, error) {
+	var hold models.Hold
+	if err := db.First(&hold, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.NewWalletError(utils.CodeHoldNotFound, "Hold not found", "")
+		}
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to retrieve hold", err.Error())
+	}
+	return &hold, nil
+}
+
+func (r *holdRepository) Update(hold *models.Hold) error {
+	if err := r.db.Save(hold).Error; err != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to update hold", err.Error())
+	}
+	return nil
+}
+
+func (r *holdRepository) ListExpired(now time.Time, limit int) ([]models.Hold, error) {
+	var holds []models.Hold
+	err := r.db.Where("status = ? AND expires_at <= ?", models.HoldStatusActive, now).
+		Order("expires_at").
+		Limit(limit).
+		Find(&holds).Error
+	if err != nil {
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to list expired holds", err.Error())
+	}
+	return holds, nil
+}
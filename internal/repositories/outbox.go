@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"time"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/pkg/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// outboxLockID is the Postgres advisory lock key held while claiming outbox events
+const outboxLockID = 7_302_019
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+type OutboxRepository interface {
+	// WithTx returns a repository bound to the given database transaction
+	WithTx(tx *gorm.DB) OutboxRepository
+
+	// Create appends an event to the outbox
+	Create(event *models.OutboxEvent) error
+
+	// ClaimDue locks up to limit unpublished events that are due, in
+	// publishing order, and postpones them by lease so other dispatchers skip
+	// them while they are sent. An event is only claimed if no earlier event
+	// of its wallet is waiting for a retry or claimed by someone else.
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]models.OutboxEvent, error)
+
+	// MarkPublished marks events as published
+	MarkPublished(ids []int64, at time.Time) error
+
+	// Reschedule makes claimed events due again at the given time
+	Reschedule(ids []int64, at time.Time) error
+
+	// Update saves the attempt counters of an event
+	Update(event *models.OutboxEvent) error
+
+	// DeletePublishedBefore removes events that were published before t
+	DeletePublishedBefore(t time.Time) (int64, error)
+}
+
+// NewOutboxRepository creates a new outbox repository
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) WithTx(tx *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: tx}
+}
+
+func (r *outboxRepository) Create(event *models.OutboxEvent) error {
+	if err := r.db.Create(event).Error; err != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to write outbox event", err.Error())
+	}
+	return nil
+}
+
+func (r *outboxRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// klaim berurutan antar instance, supaya event sebuah wallet tidak
+		// diambil dua dispatcher sekaligus; hanya selama transaksi pendek ini
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", outboxLockID).Error; err != nil {
+			return err
+		}
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND next_attempt_at <= ?", now).
+			Where(`NOT EXISTS (
+				SELECT 1 FROM outbox earlier
+				WHERE earlier.wallet_user_id = outbox.wallet_user_id
+					AND earlier.published_at IS NULL
+					AND earlier.id < outbox.id
+					AND earlier.next_attempt_at > ?
+			)`, now).
+			Order("id").
+			Limit(limit).
+			Find(&events).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		ids := make([]int64, len(events))
+		for i := range events {
+			ids[i] = events[i].ID
+		}
+		return tx.Model(&models.OutboxEvent{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to claim outbox events", err.Error())
+	}
+	return events, nil
+}
+
+func (r *outboxRepository) MarkPublished(ids []int64, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := r.db.Model(&models.OutboxEvent{}).Where("id IN ?", ids).Update("published_at", at).Error; err != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to mark outbox events as published", err.Error())
+	}
+	return nil
+}
+
+func (r *outboxRepository) Reschedule(ids []int64, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := r.db.Model(&models.OutboxEvent{}).Where("id IN ?", ids).Update("next_attempt_at", at).Error; err != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to reschedule outbox events", err.Error())
+	}
+	return nil
+}
+
+func (r *outboxRepository) Update(event *models.OutboxEvent) error {
+	if err := r.db.Save(event).Error; err != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to update outbox event", err.Error())
+	}
+	return nil
+}
+
+func (r *outboxRepository) DeletePublishedBefore(t time.Time) (int64, error) {
+	result := r.db.Where("published_at < ?", t).Delete(&models.OutboxEvent{})
+	if result.Error != nil {
+		return 0, utils.NewWalletError(utils.CodeDatabaseError, "Failed to prune outbox", result.Error.Error())
+	}
+	return result.RowsAffected, nil
+}
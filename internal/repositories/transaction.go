@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"time"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// TransactionFilter narrows down a ledger listing. Results are ordered from
+// newest to oldest; AfterCreatedAt/AfterID continue after a previous page.
+type TransactionFilter struct {
+	WalletUserID   string
+	BalanceType    string
+	Direction      string
+	From           *time.Time
+	To             *time.Time
+	Reference      string
+	AfterCreatedAt *time.Time
+	AfterID        string
+	Limit          int
+}
+
+type transactionRepository struct {
+	db *gorm.DB
+}
+
+// TransactionRepository reads and appends ledger entries. Entries recorded
+// under a balance type that was renamed since are returned with the current
+// name; the stored rows keep the old one.
+type TransactionRepository interface {
+	// WithTx returns a repository bound to the given database transaction
+	WithTx(tx *gorm.DB) TransactionRepository
+
+	// Create appends a new entry to the ledger
+	Create(transaction *models.Transaction) error
+
+	// GetByID retrieves a ledger entry by its ID
+	GetByID(id string) (*models.Transaction, error)
+
+	// FindByIdempotencyKey returns the entry written for a wallet under the
+	// given idempotency key, or nil if there is none
+	FindByIdempotencyKey(walletUserID, key string) (*models.Transaction, error)
+
+	// ListByTransferID returns both legs of a transfer
+	ListByTransferID(transferID string) ([]models.Transaction, error)
+
+	// SumReversals returns the total amount already credited back for a debit
+	SumReversals(transactionID string) (decimal.Decimal, error)
+
+	// List returns ledger entries matching the filter, newest first. A
+	// balance type filter also matches entries recorded under its former names.
+	List(filter TransactionFilter) ([]models.Transaction, error)
+}
+
+// NewTransactionRepository creates a new transaction repository
+func NewTransactionRepository(db *gorm.DB) TransactionRepository {
+	return &transactionRepository{db: db}
+}
+
+func (r *transactionRepository) WithTx(tx *gorm.DB) TransactionRepository {
+	return &transactionRepository{db: tx}
+}
+
+func (r *transactionRepository) Create(transaction *models.Transaction) error {
+	if err := r.db.Create(transaction).Error; err != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to record transaction", err.Error())
+	}
+	return nil
+}
+
+func (r *transactionRepository) GetByID(id string) (*models.Transaction, error) {
+	var transaction models.Transaction
+	if err := r.db.First(&transaction, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.NewWalletError(utils.CodeTransactionNotFound, "Transaction not found", "")
+		}
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to retrieve transaction", err.Error())
+	}
+	if err := r.resolveBalanceTypes([]*models.Transaction{&transaction}); err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+func (r *transactionRepository) FindByIdempotencyKey(walletUserID, key string) (*models.Transaction, error) {
+	var transaction models.Transaction
+	err := r.db.Where("wallet_user_id = ? AND idempotency_key = ?", walletUserID, key).Limit(1).Find(&transaction).Error
+	if err != nil {
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to look up idempotency key", err.Error())
+	}
+	if transaction.ID == "" {
+		return nil, nil
+	}
+	if err := r.resolveBalanceTypes([]*models.Transaction{&transaction}); err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+func (r *transactionRepository) ListByTransferID(transferID string) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	if err := r.db.Where("transfer_id = ?", transferID).Order("created_at, id").Find(&transactions).Error; err != nil {
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to retrieve transfer", err.Error())
+	}
+	if err := r.resolveBalanceTypes(entries(transactions)); err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+func (r *transactionRepository) SumReversals(transactionID string) (decimal.Decimal, error) {
+	var total decimal.NullDecimal
+	err := r.db.Model(&models.Transaction{}).
+		Select("SUM(amount)").
+		Where("reversal_of = ?", transactionID).
+		Scan(&total).Error
+	if err != nil {
+		return decimal.Zero, utils.NewWalletError(utils.CodeDatabaseError, "Failed to sum reversals", err.Error())
+	}
+	if !total.Valid {
+		return decimal.Zero, nil
+	}
+	return total.Decimal, nil
+}
+
+func (r *transactionRepository) List(filter TransactionFilter) ([]models.Transaction, error) {
+	query := r.db.Model(&models.Transaction{}).Where("wallet_user_id = ?", filter.WalletUserID)
+
+	if filter.BalanceType != "" {
+		query = query.Where("(balance_type = ? OR balance_type IN (SELECT old_name FROM balance_type_aliases WHERE new_name = ?))", filter.BalanceType, filter.BalanceType)
+	}
+	switch filter.Direction {
+	case models.DirectionCredit:
+		query = query.Where("amount > 0")
+	case models.DirectionDebit:
+		query = query.Where("amount < 0")
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if filter.Reference != "" {
+		query = query.Where("reference = ?", filter.Reference)
+	}
+	if filter.AfterCreatedAt != nil {
+		query = query.Where("(created_at, id) < (?, ?)", *filter.AfterCreatedAt, filter.AfterID)
+	}
+
+	var transactions []models.Transaction
+	if err := query.Order("created_at DESC, id DESC").Limit(filter.Limit).Find(&transactions).Error; err != nil {
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to list transactions", err.Error())
+	}
+	if err := r.resolveBalanceTypes(entries(transactions)); err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// resolveBalanceTypes replaces former balance type names of loaded entries
+// with the current ones. Only the loaded structs change, never the rows.
+func (r *transactionRepository) resolveBalanceTypes(transactions []*models.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(transactions))
+	seen := make(map[string]bool)
+	for _, transaction := range transactions {
+		if !seen[transaction.BalanceType] {
+			seen[transaction.BalanceType] = true
+			names = append(names, transaction.BalanceType)
+		}
+	}
+
+	var aliases []models.BalanceTypeAlias
+	if err := r.db.Where("old_name IN ?", names).Find(&aliases).Error; err != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to resolve balance type names", err.Error())
+	}
+	if len(aliases) == 0 {
+		return nil
+	}
+	current := make(map[string]string, len(aliases))
+	for _, alias := range aliases {
+		current[alias.OldName] = alias.NewName
+	}
+	for _, transaction := range transactions {
+		if name, ok := current[transaction.BalanceType]; ok {
+			transaction.BalanceType = name
+		}
+	}
+	return nil
+}
+
+// entries returns pointers to the elements of a loaded slice
+func entries(transactions []models.Transaction) []*models.Transaction {
+	pointers := make([]*models.Transaction, len(transactions))
+	for i := range transactions {
+		pointers[i] = &transactions[i]
+	}
+	return pointers
+}
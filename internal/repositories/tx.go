@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+)
+
+type txManager struct {
+	db *gorm.DB
+}
+
+type TxManager interface {
+	// WithinTransaction runs fn inside a single database transaction.
+	// The transaction is rolled back if fn returns an error.
+	WithinTransaction(fn func(tx *gorm.DB) error) error
+}
+
+// NewTxManager creates a new transaction manager
+func NewTxManager(db *gorm.DB) TxManager {
+	return &txManager{db: db}
+}
+
+func (m *txManager) WithinTransaction(fn func(tx *gorm.DB) error) error {
+	return m.db.Transaction(fn)
+}
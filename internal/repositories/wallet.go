@@ -3,10 +3,13 @@ package repositories
 import (
 	"e-commerce_marketplace/internal/models"
 	"e-commerce_marketplace/pkg/utils"
-	"encoding/json"
+	"fmt"
+	"time"
 
-	"gorm.io/datatypes"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type walletRepository struct {
@@ -14,11 +17,19 @@ type walletRepository struct {
 }
 
 type WalletRepository interface {
+	// WithTx returns a repository bound to the given database transaction
+	WithTx(tx *gorm.DB) WalletRepository
+
 	// Create creates a new wallet
 	Create(wallet *models.Wallet) error
 	
-	// GetByWalletUserID retrieves a wallet by wallet user ID
+	// GetByWalletUserID retrieves a wallet by wallet user ID or wallet ID
 	GetByWalletUserID(walletUserID string) (*models.Wallet, error)
+
+	// GetByWalletUserIDForUpdate retrieves a wallet by wallet user ID or wallet ID
+	// and locks its row until the surrounding transaction ends. Must be called on
+	// a repository bound with WithTx.
+	GetByWalletUserIDForUpdate(walletUserID string) (*models.Wallet, error)
 	
 	// Update updates an existing wallet
 	Update(wallet *models.Wallet) error
@@ -26,11 +37,41 @@ type WalletRepository interface {
 	// Delete soft deletes a wallet by wallet user ID
 	Delete(walletUserID string) error
 	
-	// ExistsByWalletUserID checks if a wallet exists for the given wallet user ID
+	// ListByOwnerID lists all wallets of an owner, oldest first
+	ListByOwnerID(ownerID string) ([]models.Wallet, error)
+
+	// ExistsByWalletUserID checks if a wallet exists for the given wallet user
+	// ID or, for UUIDs, has it as its wallet ID
 	ExistsByWalletUserID(walletUserID string) (bool, error)
 	
-	// UpdateBalances updates the balances field of a wallet
-	UpdateBalances(walletUserID string, balances *models.BalanceData) error
+	// UpdateStatus sets the status of a wallet
+	UpdateStatus(walletUserID, status string) error
+
+	// CreateStatusChange records a status change in the wallet's status log
+	CreateStatusChange(change *models.WalletStatusChange) error
+
+	// ListStatusChanges returns a wallet's status log, newest first
+	ListStatusChanges(walletUserID string) ([]models.WalletStatusChange, error)
+
+	// AdjustBalance atomically adds delta (which may be negative) to one
+	// balance type of a wallet and returns the updated balance row. A result
+	// below zero or below the held amount is rejected by the database.
+	AdjustBalance(walletUserID, balanceType string, delta decimal.Decimal) (*models.WalletBalance, error)
+
+	// AdjustHeld atomically adds delta (which may be negative) to the held
+	// part of one balance type and returns the updated balance row
+	AdjustHeld(walletUserID, balanceType string, delta decimal.Decimal) (*models.WalletBalance, error)
+
+	// BackfillBalanceType adds a zero balance of balanceType to every wallet
+	// that has none and returns the number of rows added
+	BackfillBalanceType(balanceType string) (int64, error)
+
+	// RenameBalanceType moves the balances and holds of a balance type to its
+	// new name in one transaction, records the old name as an alias and
+	// returns the number of wallets whose balance was moved. Ledger entries
+	// keep the old name. Zero balances already stored under the new name give
+	// way; any other balance under both names fails with CodeBalanceTypeExists.
+	RenameBalanceType(oldName, newName string) (int64, error)
 }
 
 // NewWalletRepository creates a new wallet repository
@@ -38,24 +79,63 @@ func NewWalletRepository(db *gorm.DB) WalletRepository {
 	return &walletRepository{db: db}
 }
 
+func (r *walletRepository) WithTx(tx *gorm.DB) WalletRepository {
+	return &walletRepository{db: tx}
+}
+
 func (r *walletRepository) Create(wallet *models.Wallet) error {
-	if err := r.db.Create(wallet).Error; err != nil {
-		if isUniqueConstraintError(err) {
-			return utils.NewWalletError(utils.CodeWalletExists, "Wallet already exists for this user", err.Error())
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(wallet).Error; err != nil {
+			if isUniqueConstraintError(err) {
+				return utils.NewWalletError(utils.CodeWalletExists, "Wallet already exists for this user", err.Error())
+			}
+			return utils.NewWalletError(utils.CodeDatabaseError, "Failed to create wallet", err.Error())
 		}
-		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to create wallet", err.Error())
-	}
-	return nil
+
+		if len(wallet.Balances) == 0 {
+			return nil
+		}
+		rows := make([]models.WalletBalance, 0, len(wallet.Balances))
+		for balanceType, amount := range wallet.Balances {
+			rows = append(rows, models.WalletBalance{
+				WalletUserID: wallet.WalletUserID,
+				BalanceType:  balanceType,
+				Amount:       amount,
+			})
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			return utils.NewWalletError(utils.CodeDatabaseError, "Failed to create wallet balances", err.Error())
+		}
+		return nil
+	})
 }
 
 func (r *walletRepository) GetByWalletUserID(walletUserID string) (*models.Wallet, error) {
 	var wallet models.Wallet
-	if err := r.db.First(&wallet, "wallet_user_id = ?", walletUserID).Error; err != nil {
+	if err := findWallet(func() *gorm.DB { return r.db }, walletUserID, &wallet); err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, utils.NewWalletError(utils.CodeWalletNotFound, "Wallet not found", "")
 		}
 		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to retrieve wallet", err.Error())
 	}
+	if err := r.loadBalances(&wallet); err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+func (r *walletRepository) GetByWalletUserIDForUpdate(walletUserID string) (*models.Wallet, error) {
+	var wallet models.Wallet
+	locked := func() *gorm.DB { return r.db.Clauses(clause.Locking{Strength: "UPDATE"}) }
+	if err := findWallet(locked, walletUserID, &wallet); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.NewWalletError(utils.CodeWalletNotFound, "Wallet not found", "")
+		}
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to lock wallet", err.Error())
+	}
+	if err := r.loadBalances(&wallet); err != nil {
+		return nil, err
+	}
 	return &wallet, nil
 }
 
@@ -80,34 +160,204 @@ func (r *walletRepository) Delete(walletUserID string) error {
 	return nil
 }
 
+func (r *walletRepository) ListByOwnerID(ownerID string) ([]models.Wallet, error) {
+	var wallets []models.Wallet
+	if err := r.db.Where("owner_id = ?", ownerID).Order("created_at ASC").Find(&wallets).Error; err != nil {
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to list wallets", err.Error())
+	}
+	for i := range wallets {
+		if err := r.loadBalances(&wallets[i]); err != nil {
+			return nil, err
+		}
+	}
+	return wallets, nil
+}
+
 func (r *walletRepository) ExistsByWalletUserID(walletUserID string) (bool, error) {
 	var count int64
-	if err := r.db.Model(&models.Wallet{}).Where("wallet_user_id = ?", walletUserID).Count(&count).Error; err != nil {
+	query := r.db.Model(&models.Wallet{}).Where("wallet_user_id = ?", walletUserID)
+	if _, err := uuid.Parse(walletUserID); err == nil {
+		// wallet user ID yang sama dengan ID wallet lain membuat key ambigu
+		query = query.Or("id = ?", walletUserID)
+	}
+	if err := query.Count(&count).Error; err != nil {
 		return false, utils.NewWalletError(utils.CodeDatabaseError, "Failed to check wallet existence", err.Error())
 	}
 	return count > 0, nil
 }
 
-func (r *walletRepository) UpdateBalances(walletUserID string, balances *models.BalanceData) error {
-	// Convert balances to JSON
-	balancesData, err := json.Marshal(balances)
+func (r *walletRepository) UpdateStatus(walletUserID, status string) error {
+	result := r.db.Model(&models.Wallet{}).Where("wallet_user_id = ?", walletUserID).Update("status", status)
+	if result.Error != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to update wallet status", result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return utils.NewWalletError(utils.CodeWalletNotFound, "Wallet not found", "")
+	}
+	return nil
+}
+
+func (r *walletRepository) CreateStatusChange(change *models.WalletStatusChange) error {
+	if err := r.db.Create(change).Error; err != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to record wallet status change", err.Error())
+	}
+	return nil
+}
+
+func (r *walletRepository) ListStatusChanges(walletUserID string) ([]models.WalletStatusChange, error) {
+	var changes []models.WalletStatusChange
+	if err := r.db.Where("wallet_user_id = ?", walletUserID).Order("created_at DESC").Find(&changes).Error; err != nil {
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to list wallet status changes", err.Error())
+	}
+	return changes, nil
+}
+
+func (r *walletRepository) AdjustBalance(walletUserID, balanceType string, delta decimal.Decimal) (*models.WalletBalance, error) {
+	now := time.Now()
+
+	var row models.WalletBalance
+	err := r.db.Raw(`
+		INSERT INTO wallet_balances (wallet_user_id, balance_type, amount, version, created_at, updated_at)
+		VALUES (?, ?, ?, 1, ?, ?)
+		ON CONFLICT (wallet_user_id, balance_type) DO UPDATE
+		SET amount = wallet_balances.amount + EXCLUDED.amount,
+			version = wallet_balances.version + 1,
+			updated_at = EXCLUDED.updated_at
+		RETURNING *`,
+		walletUserID, balanceType, delta, now, now,
+	).Scan(&row).Error
 	if err != nil {
-		return utils.NewWalletError(utils.CodeInternalError, "Failed to marshal balances", err.Error())
+		if isCheckConstraintError(err) {
+			return nil, utils.NewWalletError(utils.CodeInsufficientBalance, "Insufficient "+balanceType+" balance", "")
+		}
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to update wallet balance", err.Error())
 	}
 
-	// Update only the balances field
-	result := r.db.Model(&models.Wallet{}).
-		Where("wallet_user_id = ?", walletUserID).
-		Update("balances", datatypes.JSON(balancesData))
+	if err := r.db.Model(&models.Wallet{}).Where("wallet_user_id = ?", walletUserID).Update("updated_at", now).Error; err != nil {
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to update wallet", err.Error())
+	}
+	return &row, nil
+}
+
+func (r *walletRepository) AdjustHeld(walletUserID, balanceType string, delta decimal.Decimal) (*models.WalletBalance, error) {
+	var rows []models.WalletBalance
+	err := r.db.Raw(`
+		UPDATE wallet_balances
+		SET held = held + ?, version = version + 1, updated_at = ?
+		WHERE wallet_user_id = ? AND balance_type = ?
+		RETURNING *`,
+		delta, time.Now(), walletUserID, balanceType,
+	).Scan(&rows).Error
+	if err != nil {
+		if isCheckConstraintError(err) {
+			return nil, utils.NewWalletError(utils.CodeInsufficientBalance, "Insufficient available "+balanceType+" balance", "")
+		}
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to update held balance", err.Error())
+	}
+	if len(rows) == 0 {
+		return nil, utils.NewWalletError(utils.CodeInsufficientBalance, "Insufficient available "+balanceType+" balance", "")
+	}
+	return &rows[0], nil
+}
+
+func (r *walletRepository) BackfillBalanceType(balanceType string) (int64, error) {
+	now := time.Now()
+
+	result := r.db.Exec(`
+		INSERT INTO wallet_balances (wallet_user_id, balance_type, amount, version, created_at, updated_at)
+		SELECT wallet_user_id, ?, 0, 0, ?, ?
+		FROM wallets
+		WHERE deleted_at IS NULL
+		ON CONFLICT (wallet_user_id, balance_type) DO NOTHING`,
+		balanceType, now, now,
+	)
 	if result.Error != nil {
-		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to update wallet balances", result.Error.Error())
+		return 0, utils.NewWalletError(utils.CodeDatabaseError, "Failed to backfill balance type", result.Error.Error())
 	}
-	if result.RowsAffected == 0 {
-		return utils.NewWalletError(utils.CodeWalletNotFound, "Wallet not found", "")
+	return result.RowsAffected, nil
+}
+
+func (r *walletRepository) RenameBalanceType(oldName, newName string) (int64, error) {
+	var moved int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// saldo nol dari backfill nama baru tidak boleh menghalangi
+		err := tx.Exec(`
+			DELETE FROM wallet_balances nb
+			USING wallet_balances ob
+			WHERE nb.wallet_user_id = ob.wallet_user_id
+				AND nb.balance_type = ? AND ob.balance_type = ?
+				AND nb.amount = 0 AND nb.held = 0`,
+			newName, oldName,
+		).Error
+		if err != nil {
+			return utils.NewWalletError(utils.CodeDatabaseError, "Failed to rename balance type", err.Error())
+		}
+
+		result := tx.Model(&models.WalletBalance{}).Where("balance_type = ?", oldName).Update("balance_type", newName)
+		if result.Error != nil {
+			if isUniqueConstraintError(result.Error) {
+				return utils.NewWalletError(utils.CodeBalanceTypeExists, "Wallets hold balances under both names", fmt.Sprintf("cannot rename %q to %q", oldName, newName))
+			}
+			return utils.NewWalletError(utils.CodeDatabaseError, "Failed to rename balance type", result.Error.Error())
+		}
+		moved = result.RowsAffected
+
+		// hold ikut, supaya capture memakai nama baru
+		if err := tx.Model(&models.Hold{}).Where("balance_type = ?", oldName).Update("balance_type", newName).Error; err != nil {
+			return utils.NewWalletError(utils.CodeDatabaseError, "Failed to rename balance type of holds", err.Error())
+		}
+
+		// ledger tidak diubah; entry lama dibaca lewat alias. Nama baru yang
+		// dulu pernah jadi alias dipakai lagi, dan alias ke nama lama diteruskan.
+		if err := tx.Where("old_name = ?", newName).Delete(&models.BalanceTypeAlias{}).Error; err != nil {
+			return utils.NewWalletError(utils.CodeDatabaseError, "Failed to record balance type alias", err.Error())
+		}
+		if err := tx.Model(&models.BalanceTypeAlias{}).Where("new_name = ?", oldName).Update("new_name", newName).Error; err != nil {
+			return utils.NewWalletError(utils.CodeDatabaseError, "Failed to record balance type alias", err.Error())
+		}
+		err = tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "old_name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"new_name"}),
+		}).Create(&models.BalanceTypeAlias{OldName: oldName, NewName: newName}).Error
+		if err != nil {
+			return utils.NewWalletError(utils.CodeDatabaseError, "Failed to record balance type alias", err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
+	return moved, nil
+}
+
+// loadBalances fills the wallet's balances from the wallet_balances table
+func (r *walletRepository) loadBalances(wallet *models.Wallet) error {
+	var rows []models.WalletBalance
+	if err := r.db.Where("wallet_user_id = ?", wallet.WalletUserID).Find(&rows).Error; err != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to retrieve wallet balances", err.Error())
+	}
+	wallet.SetBalanceRows(rows)
 	return nil
 }
 
+// findWallet loads the wallet a key refers to. A UUID key is looked up as a
+// wallet ID first and only falls back to the wallet user ID when no wallet
+// has that ID, so a key never matches two wallets.
+func findWallet(query func() *gorm.DB, key string, wallet *models.Wallet) error {
+	if _, err := uuid.Parse(key); err == nil {
+		err := query().Where("id = ?", key).Take(wallet).Error
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+	}
+	return query().Where("wallet_user_id = ?", key).Take(wallet).Error
+}
+
+// isCheckConstraintError checks if the error is a check constraint violation
+func isCheckConstraintError(err error) bool {
+	return err != nil && containsString(err.Error(), "violates check constraint")
+}
+
 // isUniqueConstraintError checks if the error is a unique constraint violation
 func isUniqueConstraintError(err error) bool {
 	// PostgreSQL unique constraint error contains "duplicate key value"
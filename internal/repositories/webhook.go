@@ -0,0 +1,182 @@
+package repositories
+
+import (
+	"time"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/pkg/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+type WebhookRepository interface {
+	// WithTx returns a repository bound to the given database transaction
+	WithTx(tx *gorm.DB) WebhookRepository
+
+	// CreateSubscription creates a new webhook subscription
+	CreateSubscription(subscription *models.WebhookSubscription) error
+
+	// GetSubscription retrieves a webhook subscription by its ID
+	GetSubscription(id string) (*models.WebhookSubscription, error)
+
+	// ListSubscriptions returns all webhook subscriptions, oldest first
+	ListSubscriptions() ([]models.WebhookSubscription, error)
+
+	// ListActiveSubscriptions returns the subscriptions that receive events
+	ListActiveSubscriptions() ([]models.WebhookSubscription, error)
+
+	// UpdateSubscription saves changes to an existing webhook subscription
+	UpdateSubscription(subscription *models.WebhookSubscription) error
+
+	// DeleteSubscription soft deletes a webhook subscription
+	DeleteSubscription(id string) error
+
+	// CreateDeliveries queues deliveries, skipping those already queued for
+	// the same subscription and event
+	CreateDeliveries(deliveries []models.WebhookDelivery) error
+
+	// GetDelivery retrieves a delivery by its ID
+	GetDelivery(id string) (*models.WebhookDelivery, error)
+
+	// ListDeliveries returns the deliveries of a subscription, newest first,
+	// optionally filtered by status
+	ListDeliveries(subscriptionID, status string, limit int) ([]models.WebhookDelivery, error)
+
+	// ClaimDueDeliveries locks up to limit pending deliveries that are due and
+	// postpones them by lease so other dispatchers skip them while they are sent
+	ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error)
+
+	// UpdateDelivery saves the result of a delivery attempt
+	UpdateDelivery(delivery *models.WebhookDelivery) error
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) WithTx(tx *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: tx}
+}
+
+func (r *webhookRepository) CreateSubscription(subscription *models.WebhookSubscription) error {
+	if err := r.db.Create(subscription).Error; err != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to create webhook subscription", err.Error())
+	}
+	return nil
+}
+
+func (r *webhookRepository) GetSubscription(id string) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	if err := r.db.First(&subscription, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.NewWalletError(utils.CodeWebhookNotFound, "Webhook subscription not found", "")
+		}
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to retrieve webhook subscription", err.Error())
+	}
+	return &subscription, nil
+}
+
+func (r *webhookRepository) ListSubscriptions() ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	if err := r.db.Order("created_at").Find(&subscriptions).Error; err != nil {
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to list webhook subscriptions", err.Error())
+	}
+	return subscriptions, nil
+}
+
+func (r *webhookRepository) ListActiveSubscriptions() ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	if err := r.db.Where("active = ?", true).Find(&subscriptions).Error; err != nil {
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to list webhook subscriptions", err.Error())
+	}
+	return subscriptions, nil
+}
+
+func (r *webhookRepository) UpdateSubscription(subscription *models.WebhookSubscription) error {
+	if err := r.db.Save(subscription).Error; err != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to update webhook subscription", err.Error())
+	}
+	return nil
+}
+
+func (r *webhookRepository) DeleteSubscription(id string) error {
+	result := r.db.Delete(&models.WebhookSubscription{}, "id = ?", id)
+	if result.Error != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to delete webhook subscription", result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return utils.NewWalletError(utils.CodeWebhookNotFound, "Webhook subscription not found", "")
+	}
+	return nil
+}
+
+func (r *webhookRepository) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error; err != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to queue webhook deliveries", err.Error())
+	}
+	return nil
+}
+
+func (r *webhookRepository) GetDelivery(id string) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := r.db.First(&delivery, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, utils.NewWalletError(utils.CodeWebhookDeliveryNotFound, "Webhook delivery not found", "")
+		}
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to retrieve webhook delivery", err.Error())
+	}
+	return &delivery, nil
+}
+
+func (r *webhookRepository) ListDeliveries(subscriptionID, status string, limit int) ([]models.WebhookDelivery, error) {
+	query := r.db.Where("subscription_id = ?", subscriptionID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("created_at DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to list webhook deliveries", err.Error())
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepository) ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]string, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+		}
+		return tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, utils.NewWalletError(utils.CodeDatabaseError, "Failed to claim webhook deliveries", err.Error())
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	if err := r.db.Save(delivery).Error; err != nil {
+		return utils.NewWalletError(utils.CodeDatabaseError, "Failed to update webhook delivery", err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"e-commerce_marketplace/internal/handlers"
+	"e-commerce_marketplace/internal/middleware"
+	"e-commerce_marketplace/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func APIClientRoutes(app *fiber.App, clientHandler *handlers.APIClientHandler) {
+	clients := app.Group("/api/v1/admin/api-clients", middleware.RequireScope(models.ScopeWalletAdmin))
+
+	// GET /api/v1/admin/api-clients - List API clients
+	clients.Get("/", clientHandler.ListClients)
+
+	// POST /api/v1/admin/api-clients - Register an API client (returns its secret once)
+	clients.Post("/", clientHandler.CreateClient)
+
+	// PATCH /api/v1/admin/api-clients/:id - Change name, scopes or disable a client
+	clients.Patch("/:id", clientHandler.UpdateClient)
+
+	// POST /api/v1/admin/api-clients/:id/rotate-secret - Issue a new secret
+	clients.Post("/:id/rotate-secret", clientHandler.RotateSecret)
+
+	// DELETE /api/v1/admin/api-clients/:id - Delete a client
+	clients.Delete("/:id", clientHandler.DeleteClient)
+}
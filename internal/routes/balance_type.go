@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"e-commerce_marketplace/internal/handlers"
+	"e-commerce_marketplace/internal/middleware"
+	"e-commerce_marketplace/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func BalanceTypeRoutes(app *fiber.App, balanceTypeHandler *handlers.BalanceTypeHandler) {
+	balanceTypes := app.Group("/api/v1/balance-types")
+
+	// GET /api/v1/balance-types - List balance types
+	balanceTypes.Get("/", middleware.RequireScope(models.ScopeWalletRead), balanceTypeHandler.ListBalanceTypes)
+
+	// POST /api/v1/balance-types - Create a balance type (local backend only)
+	balanceTypes.Post("/", middleware.RequireScope(models.ScopeWalletAdmin), balanceTypeHandler.CreateBalanceType)
+
+	// GET /api/v1/balance-types/:name - Get balance type
+	balanceTypes.Get("/:name", middleware.RequireScope(models.ScopeWalletRead), balanceTypeHandler.GetBalanceType)
+
+	// PUT /api/v1/balance-types/:name - Update balance type (local backend only)
+	balanceTypes.Put("/:name", middleware.RequireScope(models.ScopeWalletAdmin), balanceTypeHandler.UpdateBalanceType)
+
+	// DELETE /api/v1/balance-types/:name - Delete balance type (local backend only)
+	balanceTypes.Delete("/:name", middleware.RequireScope(models.ScopeWalletAdmin), balanceTypeHandler.DeleteBalanceType)
+}
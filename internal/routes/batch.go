@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"e-commerce_marketplace/internal/handlers"
+	"e-commerce_marketplace/internal/middleware"
+	"e-commerce_marketplace/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func BatchRoutes(app *fiber.App, batchHandler *handlers.BatchHandler) {
+	batches := app.Group("/api/v1/batches")
+
+	// POST /api/v1/batches - Submit a batch credit (JSON or CSV upload)
+	batches.Post("/", middleware.RequireScope(models.ScopeWalletCredit), batchHandler.CreateBatch)
+
+	// GET /api/v1/batches/:id - Get batch progress
+	batches.Get("/:id", middleware.RequireScope(models.ScopeWalletRead), batchHandler.GetBatch)
+
+	// GET /api/v1/batches/:id/items - List per-row results
+	batches.Get("/:id/items", middleware.RequireScope(models.ScopeWalletRead), batchHandler.ListBatchItems)
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"e-commerce_marketplace/internal/handlers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FrappeRoutes registers the endpoints Frappe calls. They live outside
+// /api/v1 because Frappe authenticates with a webhook signature instead of
+// API credentials.
+func FrappeRoutes(app *fiber.App, frappeHandler *handlers.FrappeHandler) {
+	frappe := app.Group("/integrations/frappe")
+
+	// POST /integrations/frappe/balance-types - Balance Type doc events (insert, rename, delete)
+	frappe.Post("/balance-types", frappeHandler.BalanceTypeEvent)
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"e-commerce_marketplace/internal/handlers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HealthRoutes registers the probes for the orchestrator. They need no
+// credentials.
+func HealthRoutes(app *fiber.App, healthHandler *handlers.HealthHandler) {
+	// GET /healthz - Liveness: the process is up
+	app.Get("/healthz", healthHandler.Health)
+
+	// GET /readyz - Readiness: database and balance type source are reachable
+	app.Get("/readyz", healthHandler.Ready)
+}
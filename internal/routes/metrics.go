@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"e-commerce_marketplace/internal/metrics"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+)
+
+// MetricsRoutes registers the Prometheus scrape endpoint. It needs no
+// credentials, so keep it reachable from the monitoring network only.
+func MetricsRoutes(app *fiber.App) {
+	// GET /metrics - Prometheus metrics
+	app.Get("/metrics", adaptor.HTTPHandler(metrics.Handler()))
+}
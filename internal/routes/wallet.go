@@ -2,11 +2,16 @@ package routes
 
 import (
 	"e-commerce_marketplace/internal/handlers"
+	"e-commerce_marketplace/internal/middleware"
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/internal/services"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func WalletRoutes(app *fiber.App, walletHandler *handlers.WalletHandler) {
+// WalletRoutes registers the wallet routes. walletService is used to check
+// wallet ownership for end-user tokens.
+func WalletRoutes(app *fiber.App, walletHandler *handlers.WalletHandler, walletService services.WalletService) {
 	// API version prefix
 	api := app.Group("/api/v1")
 	
@@ -14,14 +19,47 @@ func WalletRoutes(app *fiber.App, walletHandler *handlers.WalletHandler) {
 	wallets := api.Group("/wallets")
 	
 	// POST /api/v1/wallets - Create a new wallet
-	wallets.Post("/", walletHandler.CreateWallet)
+	wallets.Post("/", middleware.RequireScope(models.ScopeWalletCreate), walletHandler.CreateWallet)
 	
-	// GET /api/v1/wallets/:id - Get wallet by ID
-	wallets.Get("/:id", walletHandler.GetWallet)
+	// GET /api/v1/wallets/:id - Get wallet by wallet ID or wallet user ID
+	wallets.Get("/:id", middleware.RequireScopeOrWalletOwner(models.ScopeWalletRead, walletService), walletHandler.GetWallet)
 	
 	// POST /api/v1/wallets/:id/add - Add balance
-	wallets.Post("/:id/add", walletHandler.AddBalance)
+	wallets.Post("/:id/add", middleware.RequireScope(models.ScopeWalletCredit), walletHandler.AddBalance)
 	
 	// POST /api/v1/wallets/:id/deduct - Deduct balance
-	wallets.Post("/:id/deduct", walletHandler.DeductBalance)
+	wallets.Post("/:id/deduct", middleware.RequireScope(models.ScopeWalletDebit), walletHandler.DeductBalance)
+
+	// GET /api/v1/wallets/:id/transactions - List transaction history
+	wallets.Get("/:id/transactions", middleware.RequireScopeOrWalletOwner(models.ScopeWalletRead, walletService), walletHandler.ListTransactions)
+
+	// POST /api/v1/wallets/:id/holds - Reserve balance
+	wallets.Post("/:id/holds", middleware.RequireScope(models.ScopeWalletDebit), walletHandler.CreateHold)
+
+	// GET /api/v1/wallets/:id/holds/:holdId - Get hold
+	wallets.Get("/:id/holds/:holdId", middleware.RequireScope(models.ScopeWalletRead), walletHandler.GetHold)
+
+	// POST /api/v1/wallets/:id/holds/:holdId/capture - Capture hold (full or partial)
+	wallets.Post("/:id/holds/:holdId/capture", middleware.RequireScope(models.ScopeWalletDebit), walletHandler.CaptureHold)
+
+	// POST /api/v1/wallets/:id/holds/:holdId/release - Release hold
+	wallets.Post("/:id/holds/:holdId/release", middleware.RequireScope(models.ScopeWalletDebit), walletHandler.ReleaseHold)
+
+	// GET /api/v1/users/:userId/wallets - List wallets owned by a user
+	api.Get("/users/:userId/wallets", middleware.RequireScopeOrSelf(models.ScopeWalletRead, "userId"), walletHandler.ListUserWallets)
+
+	// POST /api/v1/transfers - Transfer balance between wallets
+	api.Post("/transfers", middleware.RequireScope(models.ScopeWalletDebit), walletHandler.Transfer)
+
+	// POST /api/v1/transactions/:txId/reverse - Refund a debit (full or partial)
+	api.Post("/transactions/:txId/reverse", middleware.RequireScope(models.ScopeWalletCredit), walletHandler.ReverseTransaction)
+
+	// Admin routes
+	admin := api.Group("/admin/wallets", middleware.RequireScope(models.ScopeWalletAdmin))
+
+	// PUT /api/v1/admin/wallets/:id/status - Freeze, block debits, reactivate or close a wallet
+	admin.Put("/:id/status", walletHandler.ChangeWalletStatus)
+
+	// GET /api/v1/admin/wallets/:id/status-history - List status changes with reasons
+	admin.Get("/:id/status-history", walletHandler.ListWalletStatusChanges)
 }
\ No newline at end of file
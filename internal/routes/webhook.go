@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"e-commerce_marketplace/internal/handlers"
+	"e-commerce_marketplace/internal/middleware"
+	"e-commerce_marketplace/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func WebhookRoutes(app *fiber.App, webhookHandler *handlers.WebhookHandler) {
+	webhooks := app.Group("/api/v1/webhooks", middleware.RequireScope(models.ScopeWalletAdmin))
+
+	// GET /api/v1/webhooks - List webhook subscriptions
+	webhooks.Get("/", webhookHandler.ListSubscriptions)
+
+	// POST /api/v1/webhooks - Subscribe a URL to events (returns its secret once)
+	webhooks.Post("/", webhookHandler.CreateSubscription)
+
+	// POST /api/v1/webhooks/deliveries/:deliveryId/redeliver - Queue a delivery again
+	webhooks.Post("/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver)
+
+	// GET /api/v1/webhooks/:id - Get webhook subscription
+	webhooks.Get("/:id", webhookHandler.GetSubscription)
+
+	// PATCH /api/v1/webhooks/:id - Change URL, event types or deactivate
+	webhooks.Patch("/:id", webhookHandler.UpdateSubscription)
+
+	// DELETE /api/v1/webhooks/:id - Delete webhook subscription
+	webhooks.Delete("/:id", webhookHandler.DeleteSubscription)
+
+	// GET /api/v1/webhooks/:id/deliveries - List deliveries, e.g. ?status=dead
+	webhooks.Get("/:id/deliveries", webhookHandler.ListDeliveries)
+}
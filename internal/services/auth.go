@@ -0,0 +1,187 @@
+package services
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/internal/repositories"
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Principal is the authenticated caller of a request: either an API client
+// or, for end-user tokens, the user in UserID. End users have no scopes and
+// may only read wallets they own.
+type Principal struct {
+	ClientID   string
+	ClientName string
+	UserID     string
+	Scopes     []string
+}
+
+// IsUser reports whether the caller is an end user rather than an API client
+func (p *Principal) IsUser() bool {
+	return p.UserID != ""
+}
+
+// HasScope reports whether the caller was granted scope
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// JWTConfig configures JWT verification. Without Keys, the tokens it
+// describes are rejected.
+type JWTConfig struct {
+	Keys     *utils.JWKS
+	Issuer   string
+	Audience string
+}
+
+type AuthService interface {
+	// AuthenticateToken checks an API key and secret pair
+	AuthenticateToken(apiKey, apiSecret string) (*Principal, error)
+
+	// AuthenticateJWT verifies a signed JWT. Tokens whose subject is an API
+	// client ID authenticate that client; otherwise, if end-user tokens are
+	// enabled, the subject is taken as the user ID.
+	AuthenticateJWT(token string) (*Principal, error)
+}
+
+type authService struct {
+	clientRepo   repositories.APIClientRepository
+	clientJWT    JWTConfig
+	userJWT      JWTConfig
+	clientParser *jwt.Parser
+	userParser   *jwt.Parser
+}
+
+// NewAuthService creates a new authentication service. clientJWT verifies
+// service tokens; userJWT verifies end-user tokens, e.g. from the mobile app.
+func NewAuthService(clientRepo repositories.APIClientRepository, clientJWT, userJWT JWTConfig) AuthService {
+	return &authService{
+		clientRepo:   clientRepo,
+		clientJWT:    clientJWT,
+		userJWT:      userJWT,
+		clientParser: newJWTParser(clientJWT),
+		userParser:   newJWTParser(userJWT),
+	}
+}
+
+func newJWTParser(config JWTConfig) *jwt.Parser {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithExpirationRequired(),
+	}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+	return jwt.NewParser(options...)
+}
+
+func (s *authService) AuthenticateToken(apiKey, apiSecret string) (*Principal, error) {
+	client, err := s.activeClient(s.clientRepo.GetByAPIKey(apiKey))
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(client.SecretHash), []byte(hashSecret(apiSecret))) != 1 {
+		return nil, utils.NewWalletError(utils.CodeUnauthorized, "Invalid API credentials", "")
+	}
+
+	return &Principal{ClientID: client.ID, ClientName: client.Name, Scopes: client.ScopeList()}, nil
+}
+
+func (s *authService) AuthenticateJWT(token string) (*Principal, error) {
+	if s.clientJWT.Keys == nil && s.userJWT.Keys == nil {
+		return nil, utils.NewWalletError(utils.CodeUnauthorized, "Bearer tokens are not accepted", "")
+	}
+
+	if s.clientJWT.Keys != nil {
+		principal, err := s.authenticateClientJWT(token)
+		if err == nil || s.userJWT.Keys == nil {
+			return principal, err
+		}
+	}
+	return s.authenticateUserJWT(token)
+}
+
+// authenticateClientJWT verifies a service token whose subject is an API client ID
+func (s *authService) authenticateClientJWT(token string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	if _, err := s.clientParser.ParseWithClaims(token, claims, s.clientJWT.Keys.Keyfunc); err != nil {
+		return nil, utils.NewWalletError(utils.CodeUnauthorized, "Invalid token", err.Error())
+	}
+
+	subject, _ := claims.GetSubject()
+	client, err := s.activeClient(s.clientRepo.GetByID(subject))
+	if err != nil {
+		return nil, err
+	}
+
+	// scope di token tidak boleh melebihi scope client
+	scopes := client.ScopeList()
+	if requested, ok := tokenScopes(claims); ok {
+		scopes = nil
+		for _, scope := range requested {
+			if client.HasScope(scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	return &Principal{ClientID: client.ID, ClientName: client.Name, Scopes: scopes}, nil
+}
+
+// authenticateUserJWT verifies an end-user token; its subject is the user ID
+func (s *authService) authenticateUserJWT(token string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	if _, err := s.userParser.ParseWithClaims(token, claims, s.userJWT.Keys.Keyfunc); err != nil {
+		return nil, utils.NewWalletError(utils.CodeUnauthorized, "Invalid token", err.Error())
+	}
+
+	subject, _ := claims.GetSubject()
+	if subject == "" {
+		return nil, utils.NewWalletError(utils.CodeUnauthorized, "Invalid token", "missing subject")
+	}
+	return &Principal{UserID: subject}, nil
+}
+
+// activeClient turns a client lookup into an authentication result
+func (s *authService) activeClient(client *models.APIClient, err error) (*models.APIClient, error) {
+	if err != nil {
+		if utils.GetErrorCode(err) == utils.CodeAPIClientNotFound {
+			return nil, utils.NewWalletError(utils.CodeUnauthorized, "Invalid API credentials", "")
+		}
+		return nil, err
+	}
+	if client.Disabled {
+		return nil, utils.NewWalletError(utils.CodeUnauthorized, "API client is disabled", "")
+	}
+	return client, nil
+}
+
+// tokenScopes reads the "scope" (space-separated) or "scp" (list) claim
+func tokenScopes(claims jwt.MapClaims) ([]string, bool) {
+	if scope, ok := claims["scope"].(string); ok {
+		return strings.Fields(scope), true
+	}
+	if scp, ok := claims["scp"].([]interface{}); ok {
+		scopes := make([]string, 0, len(scp))
+		for _, s := range scp {
+			if scope, ok := s.(string); ok {
+				scopes = append(scopes, scope)
+			}
+		}
+		return scopes, true
+	}
+	return nil, false
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"fmt"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/shopspring/decimal"
+)
+
+type BalanceTypeService interface {
+	ListBalanceTypes() ([]models.BalanceType, error)
+	GetBalanceType(name string) (*models.BalanceType, error)
+	CreateBalanceType(req *utils.CreateBalanceTypeRequest) (*models.BalanceType, error)
+	UpdateBalanceType(name string, req *utils.UpdateBalanceTypeRequest) (*models.BalanceType, error)
+	DeleteBalanceType(name string) error
+}
+
+type balanceTypeService struct {
+	registry BalanceTypeRegistry
+	provider BalanceTypeProvider
+}
+
+// NewBalanceTypeService creates a new balance type service. Reads go through
+// the provider's cache; writes go to the registry and invalidate the cache.
+func NewBalanceTypeService(registry BalanceTypeRegistry, provider BalanceTypeProvider) BalanceTypeService {
+	return &balanceTypeService{
+		registry: registry,
+		provider: provider,
+	}
+}
+
+func (s *balanceTypeService) ListBalanceTypes() ([]models.BalanceType, error) {
+	return s.provider.BalanceTypes()
+}
+
+func (s *balanceTypeService) GetBalanceType(name string) (*models.BalanceType, error) {
+	balanceType, err := s.provider.Get(name)
+	if utils.GetErrorCode(err) == utils.CodeInvalidBalanceType {
+		return nil, utils.NewWalletError(utils.CodeBalanceTypeNotFound, "Balance type not found", "")
+	}
+	return balanceType, err
+}
+
+func (s *balanceTypeService) CreateBalanceType(req *utils.CreateBalanceTypeRequest) (*models.BalanceType, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, utils.NewWalletError(utils.CodeValidationError, "Validation failed", fmt.Sprintf("%s: %s", validationErrors[0].Field, validationErrors[0].Message))
+	}
+
+	balanceType := &models.BalanceType{
+		Name:         req.Name,
+		Scale:        utils.DefaultBalanceScale,
+		Transferable: true,
+	}
+	if err := applyBalanceTypeSettings(balanceType, &req.UpdateBalanceTypeRequest); err != nil {
+		return nil, err
+	}
+
+	if err := s.registry.Create(balanceType); err != nil {
+		return nil, err
+	}
+	s.provider.Put(*balanceType)
+	return balanceType, nil
+}
+
+func (s *balanceTypeService) UpdateBalanceType(name string, req *utils.UpdateBalanceTypeRequest) (*models.BalanceType, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, utils.NewWalletError(utils.CodeValidationError, "Validation failed", fmt.Sprintf("%s: %s", validationErrors[0].Field, validationErrors[0].Message))
+	}
+
+	balanceType, err := s.GetBalanceType(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyBalanceTypeSettings(balanceType, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.registry.Update(balanceType); err != nil {
+		return nil, err
+	}
+	s.provider.Put(*balanceType)
+	return balanceType, nil
+}
+
+func (s *balanceTypeService) DeleteBalanceType(name string) error {
+	if err := s.registry.Delete(name); err != nil {
+		return err
+	}
+	s.provider.Remove(name)
+	return nil
+}
+
+// applyBalanceTypeSettings copies the fields set in req onto balanceType
+func applyBalanceTypeSettings(balanceType *models.BalanceType, req *utils.UpdateBalanceTypeRequest) error {
+	if req.Scale != nil {
+		balanceType.Scale = *req.Scale
+	}
+	if req.Transferable != nil {
+		balanceType.Transferable = *req.Transferable
+	}
+	if req.Withdrawable != nil {
+		balanceType.Withdrawable = *req.Withdrawable
+	}
+
+	var err error
+	if req.MinPerTransaction != nil {
+		if balanceType.MinPerTransaction, err = parseLimit(*req.MinPerTransaction); err != nil {
+			return err
+		}
+	}
+	if req.MaxPerTransaction != nil {
+		if balanceType.MaxPerTransaction, err = parseLimit(*req.MaxPerTransaction); err != nil {
+			return err
+		}
+	}
+
+	min, max := balanceType.MinPerTransaction, balanceType.MaxPerTransaction
+	if min.Valid && max.Valid && min.Decimal.GreaterThan(max.Decimal) {
+		return utils.NewWalletError(utils.CodeValidationError, "min_per_transaction must not exceed max_per_transaction", "")
+	}
+	return nil
+}
+
+// parseLimit parses a per-transaction limit; an empty string removes the limit
+func parseLimit(value string) (decimal.NullDecimal, error) {
+	if value == "" {
+		return decimal.NullDecimal{}, nil
+	}
+	limit, err := decimal.NewFromString(value)
+	if err != nil || !limit.IsPositive() {
+		return decimal.NullDecimal{}, utils.NewWalletError(utils.CodeValidationError, "Per-transaction limits must be positive numbers", value)
+	}
+	return decimal.NewNullDecimal(limit), nil
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/pkg/utils"
+)
+
+// BalanceTypeProvider answers which balance types exist and how they behave
+type BalanceTypeProvider interface {
+	// BalanceTypes returns all balance types ordered by name
+	BalanceTypes() ([]models.BalanceType, error)
+
+	// Get returns a balance type, or CodeInvalidBalanceType if it does not exist
+	Get(name string) (*models.BalanceType, error)
+
+	// Invalidate marks the cached list as stale so the next call reloads it
+	Invalidate()
+
+	// Put stores or replaces one balance type in the cache, e.g. when the
+	// registry pushes a change
+	Put(balanceType models.BalanceType)
+
+	// Remove drops one balance type from the cache
+	Remove(name string)
+
+	// Loaded reports whether a balance type list has been loaded, so requests
+	// can be served even while the registry is unreachable
+	Loaded() bool
+}
+
+// BalanceTypeProviderConfig tunes caching and resiliency of the provider
+type BalanceTypeProviderConfig struct {
+	// TTL is how long a fetched list is considered fresh
+	TTL time.Duration
+	// RefreshInterval is how often the list is refreshed in the background
+	RefreshInterval time.Duration
+	// MissRefreshInterval is the minimum time between refreshes triggered by
+	// an unknown balance type
+	MissRefreshInterval time.Duration
+	// Timeout bounds a single fetch attempt
+	Timeout time.Duration
+	// MaxRetries is the number of retries after a failed attempt
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry; it doubles each retry
+	RetryBackoff time.Duration
+	// BreakerThreshold consecutive failures open the circuit for BreakerCooldown
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// DefaultBalanceTypeProviderConfig returns the default provider settings
+func DefaultBalanceTypeProviderConfig() BalanceTypeProviderConfig {
+	return BalanceTypeProviderConfig{
+		TTL:                 5 * time.Minute,
+		RefreshInterval:     time.Minute,
+		MissRefreshInterval: 10 * time.Second,
+		Timeout:             3 * time.Second,
+		MaxRetries:          2,
+		RetryBackoff:        200 * time.Millisecond,
+		BreakerThreshold:    5,
+		BreakerCooldown:     30 * time.Second,
+	}
+}
+
+// CachedBalanceTypeProvider keeps balance types in memory and refreshes them
+// from a registry. When the registry fails, the last known list is served.
+type CachedBalanceTypeProvider struct {
+	registry BalanceTypeRegistry
+	config   BalanceTypeProviderConfig
+	breaker  *utils.CircuitBreaker
+
+	mu        sync.RWMutex
+	types     map[string]models.BalanceType
+	fetchedAt time.Time
+
+	// refreshMu makes concurrent refreshes share one fetch
+	refreshMu sync.Mutex
+}
+
+// NewBalanceTypeProvider creates a new cached balance type provider
+func NewBalanceTypeProvider(registry BalanceTypeRegistry, config BalanceTypeProviderConfig) *CachedBalanceTypeProvider {
+	return &CachedBalanceTypeProvider{
+		registry: registry,
+		config:   config,
+		breaker:  utils.NewCircuitBreaker(config.BreakerThreshold, config.BreakerCooldown),
+	}
+}
+
+func (p *CachedBalanceTypeProvider) BalanceTypes() ([]models.BalanceType, error) {
+	types, err := p.current()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]models.BalanceType, 0, len(types))
+	for _, balanceType := range types {
+		list = append(list, balanceType)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+func (p *CachedBalanceTypeProvider) Get(name string) (*models.BalanceType, error) {
+	types, err := p.current()
+	if err != nil {
+		return nil, err
+	}
+	if balanceType, ok := types[name]; ok {
+		return &balanceType, nil
+	}
+
+	// type baru mungkin belum ada di cache, refresh sekali (dibatasi)
+	p.mu.RLock()
+	age := time.Since(p.fetchedAt)
+	p.mu.RUnlock()
+	if age >= p.config.MissRefreshInterval {
+		if types, err = p.refresh(); err == nil {
+			if balanceType, ok := types[name]; ok {
+				return &balanceType, nil
+			}
+		}
+	}
+
+	return nil, utils.NewWalletError(utils.CodeInvalidBalanceType, "invalid balance type", "type not found in the balance type registry")
+}
+
+func (p *CachedBalanceTypeProvider) Invalidate() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.fetchedAt = time.Time{}
+}
+
+func (p *CachedBalanceTypeProvider) Loaded() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.types != nil
+}
+
+func (p *CachedBalanceTypeProvider) Put(balanceType models.BalanceType) {
+	p.update(func(types map[string]models.BalanceType) {
+		types[balanceType.Name] = balanceType
+	})
+}
+
+func (p *CachedBalanceTypeProvider) Remove(name string) {
+	p.update(func(types map[string]models.BalanceType) {
+		delete(types, name)
+	})
+}
+
+// update applies fn to a copy of the cached types; readers keep using the
+// map they already got. Nothing is cached yet when types is nil, so the next
+// read loads the full list anyway.
+func (p *CachedBalanceTypeProvider) update(fn func(types map[string]models.BalanceType)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.types == nil {
+		return
+	}
+	types := make(map[string]models.BalanceType, len(p.types)+1)
+	for name, balanceType := range p.types {
+		types[name] = balanceType
+	}
+	fn(types)
+	p.types = types
+}
+
+// Run refreshes the cache every RefreshInterval until ctx is cancelled
+func (p *CachedBalanceTypeProvider) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.config.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := p.refresh(); err != nil {
+				log.Printf("[BalanceTypeProvider] background refresh failed: %v", err)
+			}
+		}
+	}
+}
+
+// current returns the cached types. A stale list is served right away while
+// one background refresh replaces it, so a slow registry never slows down
+// requests; callers only wait when nothing has been loaded yet.
+func (p *CachedBalanceTypeProvider) current() (map[string]models.BalanceType, error) {
+	p.mu.RLock()
+	types, fetchedAt := p.types, p.fetchedAt
+	p.mu.RUnlock()
+
+	if types != nil {
+		if time.Since(fetchedAt) >= p.config.TTL {
+			p.refreshInBackground()
+		}
+		return types, nil
+	}
+
+	fresh, err := p.refresh()
+	if err != nil {
+		return nil, utils.NewWalletError(utils.CodeBalanceTypeUnavailable, "Balance types are currently unavailable", err.Error())
+	}
+	return fresh, nil
+}
+
+// refreshInBackground starts a refresh unless one is already running
+func (p *CachedBalanceTypeProvider) refreshInBackground() {
+	if !p.refreshMu.TryLock() {
+		return
+	}
+	go func() {
+		defer p.refreshMu.Unlock()
+
+		if _, err := p.load(); err != nil {
+			log.Printf("[BalanceTypeProvider] serving stale balance types: %v", err)
+		}
+	}()
+}
+
+// refresh fetches the list with retries and stores it in the cache
+func (p *CachedBalanceTypeProvider) refresh() (map[string]models.BalanceType, error) {
+	p.mu.RLock()
+	startedAt := p.fetchedAt
+	p.mu.RUnlock()
+
+	p.refreshMu.Lock()
+	defer p.refreshMu.Unlock()
+
+	// refresh lain sudah selesai selagi kita menunggu lock
+	p.mu.RLock()
+	if p.types != nil && p.fetchedAt.After(startedAt) {
+		types := p.types
+		p.mu.RUnlock()
+		return types, nil
+	}
+	p.mu.RUnlock()
+
+	return p.load()
+}
+
+// load fetches the list and stores it in the cache. The caller holds refreshMu.
+func (p *CachedBalanceTypeProvider) load() (map[string]models.BalanceType, error) {
+	list, err := p.fetchWithRetry()
+	if err != nil {
+		return nil, err
+	}
+
+	types := make(map[string]models.BalanceType, len(list))
+	for _, balanceType := range list {
+		types[balanceType.Name] = balanceType
+	}
+
+	p.mu.Lock()
+	p.types = types
+	p.fetchedAt = time.Now()
+	p.mu.Unlock()
+	return types, nil
+}
+
+func (p *CachedBalanceTypeProvider) fetchWithRetry() ([]models.BalanceType, error) {
+	backoff := p.config.RetryBackoff
+
+	var lastErr error
+	for attempt := 0; attempt <= p.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		if err := p.breaker.Allow(); err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), p.config.Timeout)
+		list, err := p.registry.List(ctx)
+		cancel()
+		if err == nil {
+			p.breaker.Success()
+			return list, nil
+		}
+
+		p.breaker.Failure()
+		lastErr = err
+	}
+	return nil, lastErr
+}
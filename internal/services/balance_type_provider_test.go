@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/pkg/utils"
+)
+
+// fakeRegistry is a balance type registry whose answers the test controls
+type fakeRegistry struct {
+	mu    sync.Mutex
+	types []models.BalanceType
+	err   error
+	calls int
+
+	// block, when set, holds List until it is closed
+	block chan struct{}
+}
+
+func (r *fakeRegistry) set(types []models.BalanceType, err error, block chan struct{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.types, r.err, r.block = types, err, block
+}
+
+func (r *fakeRegistry) callCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.calls
+}
+
+func (r *fakeRegistry) List(ctx context.Context) ([]models.BalanceType, error) {
+	r.mu.Lock()
+	r.calls++
+	types, err, block := r.types, r.err, r.block
+	r.mu.Unlock()
+
+	if block != nil {
+		select {
+		case <-block:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return types, err
+}
+
+func (r *fakeRegistry) Create(balanceType *models.BalanceType) error { return nil }
+func (r *fakeRegistry) Update(balanceType *models.BalanceType) error { return nil }
+func (r *fakeRegistry) Delete(name string) error                     { return nil }
+func (r *fakeRegistry) Ping(ctx context.Context) error               { return nil }
+
+func testProviderConfig() BalanceTypeProviderConfig {
+	return BalanceTypeProviderConfig{
+		TTL:                 time.Millisecond,
+		RefreshInterval:     time.Hour,
+		MissRefreshInterval: time.Hour,
+		Timeout:             time.Second,
+		MaxRetries:          0,
+		BreakerThreshold:    2,
+		BreakerCooldown:     time.Hour,
+	}
+}
+
+func TestStaleBalanceTypesAreServedWithoutWaiting(t *testing.T) {
+	registry := &fakeRegistry{}
+	registry.set([]models.BalanceType{{Name: "Coins", Scale: 2}}, nil, nil)
+	provider := NewBalanceTypeProvider(registry, testProviderConfig())
+
+	if _, err := provider.Get("Coins"); err != nil {
+		t.Fatalf("first load: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// registry lambat: request tetap dijawab dari cache lama
+	block := make(chan struct{})
+	registry.set([]models.BalanceType{{Name: "Coins", Scale: 2}, {Name: "Gems", Scale: 0}}, nil, block)
+
+	var wg sync.WaitGroup
+	startedAt := time.Now()
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := provider.Get("Coins"); err != nil {
+				t.Errorf("get stale type: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if elapsed := time.Since(startedAt); elapsed > 500*time.Millisecond {
+		t.Errorf("requests waited %s for the slow registry", elapsed)
+	}
+	if calls := registry.callCount(); calls != 2 {
+		t.Errorf("registry was called %d times, want one background refresh", calls)
+	}
+
+	close(block)
+	deadline := time.Now().Add(time.Second)
+	for {
+		types, err := provider.BalanceTypes()
+		if err == nil && len(types) == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("background refresh did not replace the cache: %v %v", types, err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestStaleBalanceTypesAreServedWhileTheBreakerIsOpen(t *testing.T) {
+	registry := &fakeRegistry{}
+	registry.set([]models.BalanceType{{Name: "Coins", Scale: 2}}, nil, nil)
+	provider := NewBalanceTypeProvider(registry, testProviderConfig())
+
+	if _, err := provider.Get("Coins"); err != nil {
+		t.Fatalf("first load: %v", err)
+	}
+
+	registry.set(nil, errors.New("frappe is down"), nil)
+	for i := 0; i < 5; i++ {
+		time.Sleep(5 * time.Millisecond)
+		if _, err := provider.Get("Coins"); err != nil {
+			t.Fatalf("stale type was not served: %v", err)
+		}
+		// tunggu refresh di background selesai
+		provider.refreshMu.Lock()
+		provider.refreshMu.Unlock()
+	}
+
+	// setelah 2 kegagalan breaker terbuka dan registry tidak dipanggil lagi
+	if calls := registry.callCount(); calls != 3 {
+		t.Errorf("registry was called %d times, want 3", calls)
+	}
+	if !provider.breaker.Open() {
+		t.Error("breaker is not open")
+	}
+}
+
+func TestBalanceTypesUnavailableWithoutCache(t *testing.T) {
+	registry := &fakeRegistry{}
+	registry.set(nil, errors.New("frappe is down"), nil)
+	provider := NewBalanceTypeProvider(registry, testProviderConfig())
+
+	_, err := provider.BalanceTypes()
+	assertErrorCode(t, err, utils.CodeBalanceTypeUnavailable)
+	if provider.Loaded() {
+		t.Error("provider reports a loaded cache")
+	}
+}
+
+func TestUnknownBalanceType(t *testing.T) {
+	registry := &fakeRegistry{}
+	registry.set([]models.BalanceType{{Name: "Coins", Scale: 2}}, nil, nil)
+	provider := NewBalanceTypeProvider(registry, testProviderConfig())
+
+	_, err := provider.Get("Gems")
+	assertErrorCode(t, err, utils.CodeInvalidBalanceType)
+
+	provider.Put(models.BalanceType{Name: "Gems"})
+	if _, err := provider.Get("Gems"); err != nil {
+		t.Errorf("pushed type is unknown: %v", err)
+	}
+	provider.Remove("Gems")
+	_, err = provider.Get("Gems")
+	assertErrorCode(t, err, utils.CodeInvalidBalanceType)
+}
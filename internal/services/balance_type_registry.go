@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"e-commerce_marketplace/internal/metrics"
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/internal/repositories"
+	"e-commerce_marketplace/pkg/utils"
+)
+
+// Balance type registry backends
+const (
+	BalanceTypeBackendFrappe = "frappe"
+	BalanceTypeBackendLocal  = "local"
+)
+
+// BalanceTypeRegistry is the source of truth for balance types
+type BalanceTypeRegistry interface {
+	// List returns all balance types
+	List(ctx context.Context) ([]models.BalanceType, error)
+
+	// Create adds a new balance type
+	Create(balanceType *models.BalanceType) error
+
+	// Update saves changes to an existing balance type
+	Update(balanceType *models.BalanceType) error
+
+	// Delete removes a balance type
+	Delete(name string) error
+
+	// Ping checks that the registry is reachable
+	Ping(ctx context.Context) error
+}
+
+// FrappeBalanceTypeRegistry reads balance types from Frappe's "Balance Type"
+// doctype. Frappe only stores names: scales come from the configuration and
+// the other settings use defaults. The registry is read-only; types are
+// managed in Frappe.
+type FrappeBalanceTypeRegistry struct {
+	client *utils.FrappeClient
+	scales map[string]int32
+}
+
+// NewFrappeBalanceTypeRegistry creates a read-only registry backed by Frappe.
+// scales holds the decimal places of balance types, e.g. {"Coins": 2,
+// "Exp": 0}; other types use utils.DefaultBalanceScale.
+func NewFrappeBalanceTypeRegistry(client *utils.FrappeClient, scales map[string]int32) *FrappeBalanceTypeRegistry {
+	copied := make(map[string]int32, len(scales))
+	for name, scale := range scales {
+		copied[name] = scale
+	}
+	return &FrappeBalanceTypeRegistry{client: client, scales: copied}
+}
+
+func (r *FrappeBalanceTypeRegistry) List(ctx context.Context) ([]models.BalanceType, error) {
+	startedAt := time.Now()
+	names, err := r.client.FetchBalanceTypes(ctx)
+	metrics.ObserveFrappeRequest("fetch_balance_types", time.Since(startedAt), err)
+	if err != nil {
+		return nil, err
+	}
+
+	balanceTypes := make([]models.BalanceType, 0, len(names))
+	for _, name := range names {
+		balanceTypes = append(balanceTypes, r.BalanceType(name))
+	}
+	return balanceTypes, nil
+}
+
+// BalanceType builds a balance type known to Frappe by name only
+func (r *FrappeBalanceTypeRegistry) BalanceType(name string) models.BalanceType {
+	scale, ok := r.scales[name]
+	if !ok {
+		scale = utils.DefaultBalanceScale
+	}
+	return models.BalanceType{
+		Name:         name,
+		Scale:        scale,
+		Transferable: true,
+	}
+}
+
+func (r *FrappeBalanceTypeRegistry) Create(balanceType *models.BalanceType) error {
+	return errFrappeReadOnly()
+}
+
+func (r *FrappeBalanceTypeRegistry) Update(balanceType *models.BalanceType) error {
+	return errFrappeReadOnly()
+}
+
+func (r *FrappeBalanceTypeRegistry) Delete(name string) error {
+	return errFrappeReadOnly()
+}
+
+func (r *FrappeBalanceTypeRegistry) Ping(ctx context.Context) error {
+	startedAt := time.Now()
+	err := r.client.Ping(ctx)
+	metrics.ObserveFrappeRequest("ping", time.Since(startedAt), err)
+	return err
+}
+
+func errFrappeReadOnly() error {
+	return utils.NewWalletError(utils.CodeBalanceTypeReadOnly, "Balance types are managed in Frappe", "set BALANCE_TYPE_BACKEND=local to manage them here")
+}
+
+// localBalanceTypeRegistry stores balance types in Postgres
+type localBalanceTypeRegistry struct {
+	balanceTypeRepo repositories.BalanceTypeRepository
+}
+
+// NewLocalBalanceTypeRegistry creates a registry backed by the balance_types table
+func NewLocalBalanceTypeRegistry(balanceTypeRepo repositories.BalanceTypeRepository) BalanceTypeRegistry {
+	return &localBalanceTypeRegistry{balanceTypeRepo: balanceTypeRepo}
+}
+
+func (r *localBalanceTypeRegistry) List(ctx context.Context) ([]models.BalanceType, error) {
+	return r.balanceTypeRepo.List()
+}
+
+func (r *localBalanceTypeRegistry) Create(balanceType *models.BalanceType) error {
+	return r.balanceTypeRepo.Create(balanceType)
+}
+
+func (r *localBalanceTypeRegistry) Update(balanceType *models.BalanceType) error {
+	return r.balanceTypeRepo.Update(balanceType)
+}
+
+func (r *localBalanceTypeRegistry) Delete(name string) error {
+	return r.balanceTypeRepo.Delete(name)
+}
+
+// Ping always succeeds; the database is checked on its own
+func (r *localBalanceTypeRegistry) Ping(ctx context.Context) error {
+	return nil
+}
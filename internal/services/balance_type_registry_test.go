@@ -0,0 +1,33 @@
+package services
+
+import (
+	"testing"
+
+	"e-commerce_marketplace/pkg/utils"
+)
+
+func TestFrappeBalanceTypeScales(t *testing.T) {
+	scales := map[string]int32{"Coins": 4, "Exp": 0, "Gems": 0}
+	registry := NewFrappeBalanceTypeRegistry(nil, scales)
+
+	for name, want := range map[string]int32{
+		"Coins":  4,
+		"Exp":    0,
+		"Gems":   0,
+		"Points": utils.DefaultBalanceScale,
+	} {
+		balanceType := registry.BalanceType(name)
+		if balanceType.Name != name || balanceType.Scale != want {
+			t.Errorf("%s: got %s with scale %d, want scale %d", name, balanceType.Name, balanceType.Scale, want)
+		}
+	}
+
+	// registry menyimpan salinan; map pemanggil dan registry lain tidak berbagi state
+	scales["Gems"] = 6
+	if scale := registry.BalanceType("Gems").Scale; scale != 0 {
+		t.Errorf("Gems scale changed with the caller's map to %d", scale)
+	}
+	if scale := NewFrappeBalanceTypeRegistry(nil, nil).BalanceType("Exp").Scale; scale != utils.DefaultBalanceScale {
+		t.Errorf("registry without scales has Exp scale %d, want %d", scale, utils.DefaultBalanceScale)
+	}
+}
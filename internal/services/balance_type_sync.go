@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/internal/repositories"
+	"e-commerce_marketplace/pkg/utils"
+)
+
+// BalanceTypeSyncService applies balance type changes pushed by Frappe
+type BalanceTypeSyncService interface {
+	// HandleFrappeEvent updates the balance type cache from a Frappe doc
+	// event. It returns the affected balance type.
+	HandleFrappeEvent(req *utils.FrappeBalanceTypeEvent) (*models.BalanceType, error)
+
+	// Run backfills queued balance types until ctx is cancelled. On start it
+	// backfills every known type, so a backfill cut short by a restart is
+	// completed. It does nothing unless backfill is enabled.
+	Run(ctx context.Context)
+}
+
+type balanceTypeSyncService struct {
+	registry   *FrappeBalanceTypeRegistry
+	provider   BalanceTypeProvider
+	walletRepo repositories.WalletRepository
+	backfill   bool
+
+	// mu guards pending, the balance types waiting for a backfill; wake
+	// tells Run that there is work
+	mu      sync.Mutex
+	pending map[string]bool
+	wake    chan struct{}
+}
+
+// NewBalanceTypeSyncService creates a new balance type sync service. With
+// backfill set, new and renamed types get a zero balance in every wallet.
+func NewBalanceTypeSyncService(registry *FrappeBalanceTypeRegistry, provider BalanceTypeProvider, walletRepo repositories.WalletRepository, backfill bool) BalanceTypeSyncService {
+	return &balanceTypeSyncService{
+		registry:   registry,
+		provider:   provider,
+		walletRepo: walletRepo,
+		backfill:   backfill,
+		pending:    make(map[string]bool),
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+func (s *balanceTypeSyncService) HandleFrappeEvent(req *utils.FrappeBalanceTypeEvent) (*models.BalanceType, error) {
+	req.TypeName = strings.TrimSpace(req.TypeName)
+	req.OldTypeName = strings.TrimSpace(req.OldTypeName)
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, utils.NewWalletError(utils.CodeValidationError, "Validation failed", fmt.Sprintf("%s: %s", validationErrors[0].Field, validationErrors[0].Message))
+	}
+
+	balanceType := s.registry.BalanceType(req.TypeName)
+	switch req.Event {
+	case "delete", "on_trash":
+		s.provider.Remove(balanceType.Name)
+		log.Printf("[BalanceTypeSync] removed balance type %q", balanceType.Name)
+		return &balanceType, nil
+	case "rename", "after_rename":
+		if req.OldTypeName == "" {
+			return nil, utils.NewWalletError(utils.CodeValidationError, "Validation failed", "old_type_name: is required for rename events")
+		}
+	}
+
+	// update dari Frappe bisa berarti type_name diganti
+	renamed := req.OldTypeName != "" && req.OldTypeName != balanceType.Name
+	if renamed {
+		// saldo dipindah dulu; kalau gagal nama lama tetap dipakai sampai
+		// rename dikirim ulang
+		moved, err := s.walletRepo.RenameBalanceType(req.OldTypeName, balanceType.Name)
+		if err != nil {
+			log.Printf("[BalanceTypeSync] failed to rename balance type %q to %q: %v", req.OldTypeName, balanceType.Name, err)
+			return nil, err
+		}
+		s.provider.Remove(req.OldTypeName)
+		log.Printf("[BalanceTypeSync] moved %d wallet balances from %q to %q", moved, req.OldTypeName, balanceType.Name)
+	}
+	s.provider.Put(balanceType)
+	log.Printf("[BalanceTypeSync] stored balance type %q (%s)", balanceType.Name, req.Event)
+
+	if s.backfill && (renamed || req.Event == "insert" || req.Event == "after_insert") {
+		s.enqueueBackfill(balanceType.Name)
+	}
+	return &balanceType, nil
+}
+
+func (s *balanceTypeSyncService) Run(ctx context.Context) {
+	if !s.backfill {
+		return
+	}
+
+	types, err := s.provider.BalanceTypes()
+	if err != nil {
+		log.Printf("[BalanceTypeSync] failed to list balance types to backfill: %v", err)
+	}
+	for _, balanceType := range types {
+		s.enqueueBackfill(balanceType.Name)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		}
+		// backfill yang terputus diulang saat start berikutnya
+		for name, ok := s.nextBackfill(); ok && ctx.Err() == nil; name, ok = s.nextBackfill() {
+			s.backfillBalanceType(name)
+		}
+	}
+}
+
+// enqueueBackfill queues a balance type for Run. The webhook is answered
+// without waiting for it.
+func (s *balanceTypeSyncService) enqueueBackfill(name string) {
+	s.mu.Lock()
+	s.pending[name] = true
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// nextBackfill takes one queued balance type
+func (s *balanceTypeSyncService) nextBackfill() (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name := range s.pending {
+		delete(s.pending, name)
+		return name, true
+	}
+	return "", false
+}
+
+// backfillBalanceType adds a zero balance of the type to all wallets. Wallets
+// that already have the type are skipped, so running it again is harmless.
+func (s *balanceTypeSyncService) backfillBalanceType(name string) {
+	added, err := s.walletRepo.BackfillBalanceType(name)
+	if err != nil {
+		log.Printf("[BalanceTypeSync] failed to backfill balance type %q: %v", name, err)
+		return
+	}
+	log.Printf("[BalanceTypeSync] backfilled balance type %q into %d wallets", name, added)
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/internal/repositories"
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/shopspring/decimal"
+)
+
+func newTestSyncService(env *testEnv, backfill bool) *balanceTypeSyncService {
+	return NewBalanceTypeSyncService(NewFrappeBalanceTypeRegistry(nil, map[string]int32{"Gold": 2}), env.provider, repositories.NewWalletRepository(env.db), backfill).(*balanceTypeSyncService)
+}
+
+func TestRenameMovesBalancesAndHoldsButNotLedger(t *testing.T) {
+	env := newTestEnv(t)
+	syncService := newTestSyncService(env, false)
+
+	wallet := env.createWallet(t, map[string]string{"Coins": "10"})
+	debit, err := env.wallets.DeductBalance(wallet.WalletUserID, &utils.UpdateBalanceRequest{BalanceType: "Coins", Amount: "3"})
+	if err != nil {
+		t.Fatalf("deduct: %v", err)
+	}
+	hold, err := env.wallets.CreateHold(wallet.WalletUserID, &utils.CreateHoldRequest{BalanceType: "Coins", Amount: "4"})
+	if err != nil {
+		t.Fatalf("create hold: %v", err)
+	}
+	// saldo nol dari backfill nama baru diganti saldo yang dipindah
+	if _, err := repositories.NewWalletRepository(env.db).BackfillBalanceType("Gold"); err != nil {
+		t.Fatalf("backfill: %v", err)
+	}
+	ledgerBefore := ledgerRows(t, env, wallet.WalletUserID)
+
+	_, err = syncService.HandleFrappeEvent(&utils.FrappeBalanceTypeEvent{Event: "after_rename", TypeName: "Gold", OldTypeName: "Coins"})
+	if err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+
+	balances, err := repositories.NewWalletRepository(env.db).GetByWalletUserID(wallet.WalletUserID)
+	if err != nil {
+		t.Fatalf("get wallet: %v", err)
+	}
+	if _, ok := balances.Balances["Coins"]; ok || !balances.Balances["Gold"].Equal(decimal.NewFromInt(7)) {
+		t.Errorf("balances after rename: %v", balances.Balances)
+	}
+	if _, err := env.provider.Get("Coins"); err == nil {
+		t.Error("old name is still served after the rename")
+	}
+
+	// ledger append-only: baris lama tidak berubah sama sekali
+	if ledgerAfter := ledgerRows(t, env, wallet.WalletUserID); !reflect.DeepEqual(ledgerAfter, ledgerBefore) {
+		t.Errorf("ledger changed by the rename:\nbefore %+v\nafter  %+v", ledgerBefore, ledgerAfter)
+	}
+
+	// entry lama dibaca dengan nama baru
+	stored, err := repositories.NewTransactionRepository(env.db).GetByID(debit.Transaction.ID)
+	if err != nil {
+		t.Fatalf("get transaction: %v", err)
+	}
+	if stored.BalanceType != "Gold" {
+		t.Errorf("old debit is read as %q, want Gold", stored.BalanceType)
+	}
+	page, err := env.wallets.ListTransactions(wallet.WalletUserID, &utils.ListTransactionsRequest{BalanceType: "Gold"})
+	if err != nil {
+		t.Fatalf("list transactions: %v", err)
+	}
+	if len(page.Transactions) != 2 {
+		t.Errorf("listing by the new name found %d entries, want 2", len(page.Transactions))
+	}
+
+	// reversal dan capture memakai nama baru
+	if _, err := env.wallets.ReverseTransaction(debit.Transaction.ID, &utils.ReverseTransactionRequest{Amount: "1"}); err != nil {
+		t.Fatalf("reverse old debit: %v", err)
+	}
+	if _, err := env.wallets.CaptureHold(wallet.WalletUserID, hold.ID, &utils.CaptureHoldRequest{}); err != nil {
+		t.Fatalf("capture hold: %v", err)
+	}
+	if got := env.balance(t, wallet.WalletUserID, "Gold"); !got.Equal(decimal.NewFromInt(4)) {
+		t.Errorf("balance after reversal and capture = %s, want 4", got)
+	}
+	if n := env.countTransactions(t, wallet.WalletUserID, "balance_type = 'Gold'"); n != 2 {
+		t.Errorf("%d new entries under the new name, want 2", n)
+	}
+}
+
+// ledgerRows returns the stored ledger entries of a wallet as they are in
+// the table, without resolving renamed balance types
+func ledgerRows(t *testing.T, env *testEnv, walletUserID string) []map[string]interface{} {
+	t.Helper()
+
+	var rows []map[string]interface{}
+	err := env.db.Table("transactions").
+		Select("id, balance_type, amount::text AS amount, balance_after::text AS balance_after").
+		Where("wallet_user_id = ?", walletUserID).
+		Order("id").
+		Find(&rows).Error
+	if err != nil {
+		t.Fatalf("read ledger: %v", err)
+	}
+	return rows
+}
+
+func TestRenameFailsWhenBothNamesHoldBalances(t *testing.T) {
+	env := newTestEnv(t)
+	syncService := newTestSyncService(env, false)
+	env.provider.Put(models.BalanceType{Name: "Gold", Scale: 2})
+
+	wallet := env.createWallet(t, map[string]string{"Coins": "10", "Gold": "1"})
+
+	_, err := syncService.HandleFrappeEvent(&utils.FrappeBalanceTypeEvent{Event: "after_rename", TypeName: "Gold", OldTypeName: "Coins"})
+	assertErrorCode(t, err, utils.CodeBalanceTypeExists)
+
+	var count int64
+	env.db.Model(&models.WalletBalance{}).Where("wallet_user_id = ? AND balance_type = ?", wallet.WalletUserID, "Coins").Count(&count)
+	if count != 1 {
+		t.Errorf("failed rename moved the old balance")
+	}
+	if _, err := env.provider.Get("Coins"); err != nil {
+		t.Errorf("old name is no longer served after a failed rename: %v", err)
+	}
+	var aliases int64
+	env.db.Model(&models.BalanceTypeAlias{}).Count(&aliases)
+	if aliases != 0 {
+		t.Errorf("failed rename recorded %d aliases", aliases)
+	}
+}
+
+func TestBackfillRunsInWorker(t *testing.T) {
+	env := newTestEnv(t)
+	syncService := newTestSyncService(env, true)
+	wallet := env.createWallet(t, nil)
+
+	// tipe yang dikenal sebelum start, misalnya backfill yang terputus restart
+	env.provider.Put(models.BalanceType{Name: "Silver", Scale: 2})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		syncService.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	if _, err := syncService.HandleFrappeEvent(&utils.FrappeBalanceTypeEvent{Event: "after_insert", TypeName: "Gold"}); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	for _, balanceType := range []string{"Silver", "Gold"} {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			var count int64
+			env.db.Model(&models.WalletBalance{}).Where("wallet_user_id = ? AND balance_type = ?", wallet.WalletUserID, balanceType).Count(&count)
+			if count == 1 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("wallet was not backfilled with %s", balanceType)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+}
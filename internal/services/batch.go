@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/internal/repositories"
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+// DefaultBatchChunkSize is how many batch rows are credited between progress updates
+const DefaultBatchChunkSize = 500
+
+const defaultBatchItemPageSize = 100
+
+type BatchService interface {
+	SubmitBatch(req *utils.CreateBatchRequest) (*models.BatchResult, error)
+	GetBatch(batchID string) (*models.Batch, error)
+	ListBatchItems(batchID string, req *utils.ListBatchItemsRequest) (*models.BatchItemPage, error)
+
+	// Shutdown stops running batches after their current row and waits for
+	// them until ctx is done. Stopped batches resume when resubmitted.
+	Shutdown(ctx context.Context) error
+}
+
+type batchService struct {
+	batchRepo     repositories.BatchRepository
+	walletService WalletService
+	chunkSize     int
+
+	// mu guards running and stopped. running holds the IDs of batches
+	// processed by this instance; a batch is claimed there before any of its
+	// rows are touched, so two submissions never work on it at once.
+	mu      sync.Mutex
+	running map[string]bool
+	stopped bool
+
+	workers sync.WaitGroup
+	stop    chan struct{}
+}
+
+// NewBatchService creates a new batch service. Rows are credited through
+// walletService, one database transaction per row.
+func NewBatchService(batchRepo repositories.BatchRepository, walletService WalletService, chunkSize int) BatchService {
+	if chunkSize <= 0 {
+		chunkSize = DefaultBatchChunkSize
+	}
+	return &batchService{
+		batchRepo:     batchRepo,
+		walletService: walletService,
+		chunkSize:     chunkSize,
+		running:       make(map[string]bool),
+		stop:          make(chan struct{}),
+	}
+}
+
+func (s *batchService) SubmitBatch(req *utils.CreateBatchRequest) (*models.BatchResult, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, utils.NewWalletError(utils.CodeValidationError, "Validation failed", fmt.Sprintf("%s: %s", validationErrors[0].Field, validationErrors[0].Message))
+	}
+
+	hash := batchHash(req)
+
+	// batch yang sama dikirim ulang: lanjutkan baris yang belum berhasil
+	if req.IdempotencyKey != "" {
+		existing, err := s.batchRepo.FindByIdempotencyKey(req.IdempotencyKey)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			if existing.RequestHash != hash {
+				return nil, utils.NewWalletError(
+					utils.CodeIdempotencyConflict,
+					"Idempotency key was already used with a different batch",
+					fmt.Sprintf("idempotency key %q belongs to batch %s", req.IdempotencyKey, existing.ID),
+				)
+			}
+			if err := s.resume(existing); err != nil {
+				return nil, err
+			}
+			return &models.BatchResult{Batch: existing, Replayed: true}, nil
+		}
+	}
+
+	batch := &models.Batch{
+		ID:          uuid.New().String(),
+		Reference:   req.Reference,
+		Status:      models.BatchStatusPending,
+		TotalRows:   len(req.Items),
+		RequestHash: hash,
+	}
+	if req.IdempotencyKey != "" {
+		batch.IdempotencyKey = &req.IdempotencyKey
+	}
+
+	items := make([]models.BatchItem, len(req.Items))
+	for i, row := range req.Items {
+		items[i] = models.BatchItem{
+			RowNumber:    i + 1,
+			WalletUserID: row.WalletUserID,
+			BalanceType:  row.BalanceType,
+			Amount:       row.Amount,
+			Status:       models.BatchItemStatusPending,
+		}
+	}
+
+	// klaim sebelum batch dibuat, supaya batch tidak tertinggal tanpa
+	// diproses kalau shutdown dimulai di antaranya
+	if _, err := s.claim(batch.ID); err != nil {
+		return nil, err
+	}
+	if err := s.batchRepo.Create(batch, items); err != nil {
+		s.release(batch.ID)
+		return nil, err
+	}
+
+	s.start(batch.ID)
+	return &models.BatchResult{Batch: batch}, nil
+}
+
+func (s *batchService) GetBatch(batchID string) (*models.Batch, error) {
+	return s.batchRepo.GetByID(batchID)
+}
+
+func (s *batchService) ListBatchItems(batchID string, req *utils.ListBatchItemsRequest) (*models.BatchItemPage, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, utils.NewWalletError(utils.CodeValidationError, "Validation failed", fmt.Sprintf("%s: %s", validationErrors[0].Field, validationErrors[0].Message))
+	}
+	if _, err := s.batchRepo.GetByID(batchID); err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultBatchItemPageSize
+	}
+
+	// ambil satu baris lebih untuk tahu masih ada halaman berikutnya
+	items, err := s.batchRepo.ListItems(batchID, req.Status, req.AfterRow, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.BatchItemPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.HasMore = true
+		page.NextAfterRow = page.Items[limit-1].RowNumber
+	}
+	return page, nil
+}
+
+// resume restarts an unfinished or partly failed batch. Failed rows are
+// retried; rows that already succeeded are left alone.
+func (s *batchService) resume(batch *models.Batch) error {
+	if batch.Status == models.BatchStatusCompleted {
+		return nil
+	}
+	claimed, err := s.claim(batch.ID)
+	if err != nil || !claimed {
+		return err
+	}
+
+	if err := s.batchRepo.ResetFailedItems(batch.ID); err != nil {
+		s.release(batch.ID)
+		return err
+	}
+	batch.Status = models.BatchStatusPending
+	if err := s.batchRepo.RefreshCounts(batch); err != nil {
+		s.release(batch.ID)
+		return err
+	}
+
+	s.start(batch.ID)
+	return nil
+}
+
+func (s *batchService) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.stop)
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// claim marks a batch as processed by this instance. It reports false if the
+// batch is already running here and fails once Shutdown has been called.
+// A claimed batch must be passed to start or release.
+func (s *batchService) claim(batchID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return false, errBatchShuttingDown()
+	}
+	if s.running[batchID] {
+		return false, nil
+	}
+	s.running[batchID] = true
+	s.workers.Add(1)
+	return true, nil
+}
+
+// release gives up the claim on a batch
+func (s *batchService) release(batchID string) {
+	s.mu.Lock()
+	delete(s.running, batchID)
+	s.mu.Unlock()
+	s.workers.Done()
+}
+
+// start processes a claimed batch in the background
+func (s *batchService) start(batchID string) {
+	go func() {
+		defer s.release(batchID)
+		s.process(batchID)
+	}()
+}
+
+// stopping reports whether Shutdown was called
+func (s *batchService) stopping() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func errBatchShuttingDown() error {
+	return utils.NewWalletError(utils.CodeShuttingDown, "Service is shutting down", "submit the batch again to another instance or after the restart")
+}
+
+// process credits the pending rows of a batch chunk by chunk and refreshes
+// the batch counters after every chunk
+func (s *batchService) process(batchID string) {
+	batch, err := s.batchRepo.GetByID(batchID)
+	if err != nil {
+		log.Printf("[BatchService] failed to load batch %s: %v", batchID, err)
+		return
+	}
+	batch.Status = models.BatchStatusProcessing
+	if err := s.batchRepo.Update(batch); err != nil {
+		log.Printf("[BatchService] failed to start batch %s: %v", batchID, err)
+		return
+	}
+
+	afterRow := 0
+	for {
+		items, err := s.batchRepo.ListItems(batchID, models.BatchItemStatusPending, afterRow, s.chunkSize)
+		if err != nil {
+			log.Printf("[BatchService] failed to load rows of batch %s: %v", batchID, err)
+			return
+		}
+
+		stopped := false
+		for i := range items {
+			if stopped = s.stopping(); stopped {
+				break
+			}
+			s.processItem(batch, &items[i])
+		}
+		if err := s.batchRepo.RefreshCounts(batch); err != nil {
+			log.Printf("[BatchService] failed to update batch %s: %v", batchID, err)
+			return
+		}
+
+		// batch tetap "processing" dan dilanjutkan saat dikirim ulang
+		if stopped {
+			log.Printf("[BatchService] stopped batch %s for shutdown after %d of %d rows", batchID, batch.SucceededRows+batch.FailedRows, batch.TotalRows)
+			return
+		}
+
+		if len(items) < s.chunkSize {
+			break
+		}
+		afterRow = items[len(items)-1].RowNumber
+	}
+
+	batch.Status = models.BatchStatusCompleted
+	if batch.FailedRows > 0 {
+		batch.Status = models.BatchStatusCompletedWithErrors
+	}
+	if err := s.batchRepo.Update(batch); err != nil {
+		log.Printf("[BatchService] failed to finish batch %s: %v", batchID, err)
+	}
+}
+
+// processItem credits one batch row. The row's idempotency key is derived
+// from the batch and row number, so a row is never credited twice even if
+// the batch is resumed after its result was lost.
+func (s *batchService) processItem(batch *models.Batch, item *models.BatchItem) {
+	result, err := s.walletService.AddBalance(item.WalletUserID, &utils.UpdateBalanceRequest{
+		BalanceType:    item.BalanceType,
+		Amount:         item.Amount,
+		Reference:      batch.Reference,
+		Metadata:       map[string]interface{}{"batch_id": batch.ID, "batch_row": item.RowNumber},
+		IdempotencyKey: fmt.Sprintf("batch:%s:%d", batch.ID, item.RowNumber),
+	})
+	if err != nil {
+		item.Status = models.BatchItemStatusFailed
+		item.ErrorCode = utils.GetErrorCode(err)
+		item.ErrorMessage = err.Error()
+		if walletErr, ok := err.(*utils.WalletError); ok {
+			item.ErrorMessage = walletErr.Message
+		}
+	} else {
+		item.Status = models.BatchItemStatusSucceeded
+		item.TransactionID = &result.Transaction.ID
+	}
+
+	if err := s.batchRepo.UpdateItem(item); err != nil {
+		log.Printf("[BatchService] failed to save row %d of batch %s: %v", item.RowNumber, batch.ID, err)
+	}
+}
+
+// batchHash fingerprints a batch submission so a resubmission under the same
+// idempotency key can be told apart from a conflicting one
+func batchHash(req *utils.CreateBatchRequest) string {
+	payload, _ := json.Marshal(struct {
+		Reference string                   `json:"reference"`
+		Items     []utils.BatchItemRequest `json:"items"`
+	}{
+		Reference: req.Reference,
+		Items:     req.Items,
+	})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
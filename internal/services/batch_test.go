@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/internal/repositories"
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestClaimGuardsRunningBatches(t *testing.T) {
+	s := NewBatchService(nil, nil, 0).(*batchService)
+
+	claimed, err := s.claim("batch-1")
+	if err != nil || !claimed {
+		t.Fatalf("first claim = %v, %v", claimed, err)
+	}
+	// batch yang masih jalan tidak boleh diproses dua kali
+	if claimed, err := s.claim("batch-1"); err != nil || claimed {
+		t.Fatalf("second claim = %v, %v", claimed, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("shutdown with a running batch = %v, want deadline exceeded", err)
+	}
+
+	s.release("batch-1")
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	_, err = s.claim("batch-2")
+	assertErrorCode(t, err, utils.CodeShuttingDown)
+}
+
+func TestSubmitBatchAfterShutdown(t *testing.T) {
+	env := newTestEnv(t)
+	batches := NewBatchService(repositories.NewBatchRepository(env.db), env.wallets, 0)
+	wallet := env.createWallet(t, nil)
+
+	if err := batches.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	_, err := batches.SubmitBatch(&utils.CreateBatchRequest{
+		Items: []utils.BatchItemRequest{{WalletUserID: wallet.WalletUserID, BalanceType: "Coins", Amount: "1"}},
+	})
+	assertErrorCode(t, err, utils.CodeShuttingDown)
+
+	var count int64
+	if err := env.db.Model(&models.Batch{}).Count(&count).Error; err != nil {
+		t.Fatalf("count batches: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("rejected submission left %d batches", count)
+	}
+}
+
+func TestResubmittedBatchRetriesFailedRows(t *testing.T) {
+	env := newTestEnv(t)
+	batches := NewBatchService(repositories.NewBatchRepository(env.db), env.wallets, 0)
+	wallet := env.createWallet(t, nil)
+
+	req := &utils.CreateBatchRequest{
+		IdempotencyKey: "batch-key",
+		Items: []utils.BatchItemRequest{
+			{WalletUserID: wallet.WalletUserID, BalanceType: "Coins", Amount: "5"},
+			{WalletUserID: "missing-user", BalanceType: "Coins", Amount: "5"},
+		},
+	}
+	result, err := batches.SubmitBatch(req)
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	batch := waitForBatch(t, batches, result.Batch.ID)
+	if batch.Status != models.BatchStatusCompletedWithErrors || batch.SucceededRows != 1 || batch.FailedRows != 1 {
+		t.Fatalf("got status %s with %d succeeded and %d failed rows", batch.Status, batch.SucceededRows, batch.FailedRows)
+	}
+
+	// baris yang sudah berhasil tidak dikreditkan lagi
+	result, err = batches.SubmitBatch(req)
+	if err != nil {
+		t.Fatalf("resubmit: %v", err)
+	}
+	if !result.Replayed || result.Batch.ID != batch.ID {
+		t.Fatalf("resubmission created batch %s (replayed %v)", result.Batch.ID, result.Replayed)
+	}
+	batch = waitForBatch(t, batches, batch.ID)
+	if batch.SucceededRows != 1 || batch.FailedRows != 1 {
+		t.Errorf("after resubmit got %d succeeded and %d failed rows", batch.SucceededRows, batch.FailedRows)
+	}
+	if got := env.balance(t, wallet.WalletUserID, "Coins"); !got.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("balance = %s, want 5", got)
+	}
+}
+
+// waitForBatch polls a batch until it is no longer pending or processing
+func waitForBatch(t *testing.T, batches BatchService, batchID string) *models.Batch {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		batch, err := batches.GetBatch(batchID)
+		if err != nil {
+			t.Fatalf("get batch: %v", err)
+		}
+		if batch.Status != models.BatchStatusPending && batch.Status != models.BatchStatusProcessing {
+			return batch
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("batch %s still %s", batchID, batch.Status)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"encoding/json"
+	"time"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EventPublisher records wallet events. Publish is called inside the database
+// transaction that caused the event, so only committed changes are announced.
+type EventPublisher interface {
+	Publish(tx *gorm.DB, event *models.Event) error
+}
+
+// newEvent builds an event with data encoded as JSON
+func newEvent(eventType, walletUserID string, data interface{}) (*models.Event, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, utils.NewWalletError(utils.CodeInternalError, "Failed to encode event", err.Error())
+	}
+
+	return &models.Event{
+		ID:           uuid.New().String(),
+		Type:         eventType,
+		WalletUserID: walletUserID,
+		Data:         encoded,
+		CreatedAt:    time.Now().UTC(),
+	}, nil
+}
+
+// publish builds and publishes an event in tx
+func (s *walletService) publish(tx *gorm.DB, eventType, walletUserID string, data interface{}) error {
+	event, err := newEvent(eventType, walletUserID, data)
+	if err != nil {
+		return err
+	}
+	return s.events.Publish(tx, event)
+}
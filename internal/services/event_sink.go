@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"e-commerce_marketplace/internal/models"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Event sinks
+const (
+	EventSinkWebhook = "webhook"
+	EventSinkNATS    = "nats"
+	EventSinkStdout  = "stdout"
+)
+
+// StdoutSink writes every event as one JSON line, e.g. for log shipping
+type StdoutSink struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewStdoutSink creates a sink that writes events to out
+func NewStdoutSink(out io.Writer) *StdoutSink {
+	return &StdoutSink{out: out}
+}
+
+func (s *StdoutSink) Name() string {
+	return EventSinkStdout
+}
+
+func (s *StdoutSink) Send(ctx context.Context, event *models.Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.out.Write(append(line, '\n'))
+	return err
+}
+
+// NATSSink publishes every event to the subject "<prefix>.<event type>",
+// e.g. "wallet.balance.credited". The event ID is sent as Nats-Msg-Id so
+// JetStream streams drop redelivered duplicates.
+type NATSSink struct {
+	conn          *nats.Conn
+	subjectPrefix string
+	flushTimeout  time.Duration
+}
+
+// NewNATSSink connects to the NATS server at url
+func NewNATSSink(url, subjectPrefix string) (*NATSSink, error) {
+	conn, err := nats.Connect(url, nats.Name("wallet-service"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+	return &NATSSink{
+		conn:          conn,
+		subjectPrefix: subjectPrefix,
+		flushTimeout:  5 * time.Second,
+	}, nil
+}
+
+func (s *NATSSink) Name() string {
+	return EventSinkNATS
+}
+
+func (s *NATSSink) Send(ctx context.Context, event *models.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(s.subjectPrefix + "." + event.Type)
+	msg.Header.Set(nats.MsgIdHdr, event.ID)
+	msg.Data = data
+	if err := s.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+	// tunggu server menerima supaya event tidak ditandai terkirim terlalu cepat
+	return s.conn.FlushTimeout(s.flushTimeout)
+}
+
+// Close drains and closes the NATS connection
+func (s *NATSSink) Close() error {
+	return s.conn.Drain()
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"e-commerce_marketplace/internal/repositories"
+)
+
+// Dependency statuses. A degraded dependency is unreachable but the service
+// can still serve without it, e.g. from cached balance types.
+const (
+	DependencyUp       = "up"
+	DependencyDegraded = "degraded"
+	DependencyDown     = "down"
+)
+
+// DependencyStatus is the result of checking one dependency
+type DependencyStatus struct {
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ReadinessReport tells whether the service can serve requests
+type ReadinessReport struct {
+	Ready        bool                        `json:"ready"`
+	ShuttingDown bool                        `json:"shutting_down,omitempty"`
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+}
+
+type HealthService interface {
+	// Readiness checks all dependencies concurrently, each within the
+	// configured timeout
+	Readiness(ctx context.Context) *ReadinessReport
+
+	// SetShuttingDown makes the service report not ready from now on
+	SetShuttingDown()
+}
+
+type healthService struct {
+	healthRepo   repositories.HealthRepository
+	registry     BalanceTypeRegistry
+	provider     BalanceTypeProvider
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+// NewHealthService creates a new health service that checks the database
+// and the balance type registry
+func NewHealthService(healthRepo repositories.HealthRepository, registry BalanceTypeRegistry, provider BalanceTypeProvider, timeout time.Duration) HealthService {
+	return &healthService{
+		healthRepo: healthRepo,
+		registry:   registry,
+		provider:   provider,
+		timeout:    timeout,
+	}
+}
+
+func (s *healthService) SetShuttingDown() {
+	s.shuttingDown.Store(true)
+}
+
+func (s *healthService) Readiness(ctx context.Context) *ReadinessReport {
+	checks := map[string]func(ctx context.Context) (string, error){
+		"database":      s.checkDatabase,
+		"balance_types": s.checkBalanceTypes,
+	}
+
+	report := &ReadinessReport{
+		Ready:        !s.shuttingDown.Load(),
+		ShuttingDown: s.shuttingDown.Load(),
+		Dependencies: make(map[string]DependencyStatus, len(checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(ctx context.Context) (string, error)) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, s.timeout)
+			defer cancel()
+
+			startedAt := time.Now()
+			status, err := check(checkCtx)
+			result := DependencyStatus{Status: status, LatencyMS: time.Since(startedAt).Milliseconds()}
+			if err != nil {
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Dependencies[name] = result
+			if status == DependencyDown {
+				report.Ready = false
+			}
+		}(name, check)
+	}
+	wg.Wait()
+	return report
+}
+
+func (s *healthService) checkDatabase(ctx context.Context) (string, error) {
+	if err := s.healthRepo.Ping(ctx); err != nil {
+		return DependencyDown, err
+	}
+	return DependencyUp, nil
+}
+
+// checkBalanceTypes only fails readiness when the registry is unreachable and
+// no balance types were ever loaded
+func (s *healthService) checkBalanceTypes(ctx context.Context) (string, error) {
+	if err := s.registry.Ping(ctx); err != nil {
+		if s.provider.Loaded() {
+			return DependencyDegraded, err
+		}
+		return DependencyDown, err
+	}
+	return DependencyUp, nil
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// expiredHoldBatchSize limits how many expired holds are released per run
+const expiredHoldBatchSize = 100
+
+func (s *walletService) CreateHold(walletUserID string, req *utils.CreateHoldRequest) (*models.Hold, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, utils.NewWalletError(utils.CodeValidationError, "Validation failed", fmt.Sprintf("%s: %s", validationErrors[0].Field, validationErrors[0].Message))
+	}
+
+	amount, _, err := s.validateAmount(req.BalanceType, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := marshalMetadata(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := s.holdTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+
+	var hold *models.Hold
+	err = s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		wallet, err := s.walletRepo.WithTx(tx).GetByWalletUserIDForUpdate(walletUserID)
+		if err != nil {
+			return err
+		}
+		// hold = debit yang ditunda, jadi ikut aturan debit
+		if err := checkWalletStatus(wallet, true); err != nil {
+			return err
+		}
+
+		available := wallet.Available[req.BalanceType]
+		if available.LessThan(amount) {
+			return utils.NewWalletError(
+				utils.CodeInsufficientBalance,
+				fmt.Sprintf("Insufficient %s balance", req.BalanceType),
+				fmt.Sprintf("Available balance: %s, required: %s", available, amount),
+			)
+		}
+
+		if _, err := s.walletRepo.WithTx(tx).AdjustHeld(wallet.WalletUserID, req.BalanceType, amount); err != nil {
+			return err
+		}
+
+		hold = &models.Hold{
+			WalletUserID:   wallet.WalletUserID,
+			BalanceType:    req.BalanceType,
+			Amount:         amount,
+			CapturedAmount: decimal.Zero,
+			Status:         models.HoldStatusActive,
+			Reference:      req.Reference,
+			Metadata:       metadata,
+			ExpiresAt:      time.Now().Add(ttl),
+		}
+		return s.holdRepo.WithTx(tx).Create(hold)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+func (s *walletService) GetHold(walletUserID, holdID string) (*models.Hold, error) {
+	wallet, err := s.walletRepo.GetByWalletUserID(walletUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	hold, err := s.holdRepo.GetByID(holdID)
+	if err != nil {
+		return nil, err
+	}
+	if hold.WalletUserID != wallet.WalletUserID {
+		return nil, utils.NewWalletError(utils.CodeHoldNotFound, "Hold not found", "")
+	}
+	return hold, nil
+}
+
+func (s *walletService) CaptureHold(walletUserID, holdID string, req *utils.CaptureHoldRequest) (*models.HoldCaptureResult, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, utils.NewWalletError(utils.CodeValidationError, "Validation failed", fmt.Sprintf("%s: %s", validationErrors[0].Field, validationErrors[0].Message))
+	}
+
+	result := &models.HoldCaptureResult{}
+	err := s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		wallet, hold, err := s.lockHold(tx, walletUserID, holdID)
+		if err != nil {
+			return err
+		}
+		if !hold.ExpiresAt.After(time.Now()) {
+			return utils.NewWalletError(utils.CodeHoldNotActive, "Hold has expired", fmt.Sprintf("expired at %s", hold.ExpiresAt.Format(time.RFC3339)))
+		}
+
+		// tanpa amount berarti capture penuh
+		amount := hold.Amount
+		if req.Amount != "" {
+			amount, err = s.parseScaledAmount(hold.BalanceType, req.Amount)
+			if err != nil {
+				return err
+			}
+			if amount.GreaterThan(hold.Amount) {
+				return utils.NewWalletError(utils.CodeInvalidAmount, "Capture amount exceeds held amount", fmt.Sprintf("held: %s, requested: %s", hold.Amount, amount))
+			}
+		}
+
+		// lepas seluruh hold dulu, sisa yang tidak di-capture kembali ke available
+		row, err := s.walletRepo.WithTx(tx).AdjustHeld(wallet.WalletUserID, hold.BalanceType, hold.Amount.Neg())
+		if err != nil {
+			return err
+		}
+		wallet.SetBalanceRow(*row)
+
+		entry, err := newLedgerEntry(hold.BalanceType, amount.Neg(), hold.Reference, nil)
+		if err != nil {
+			return err
+		}
+		entry.Metadata = hold.Metadata
+		entry.HoldID = &hold.ID
+		if err := s.applyEntry(tx, wallet, entry); err != nil {
+			return err
+		}
+
+		hold.Status = models.HoldStatusCaptured
+		hold.CapturedAmount = amount
+		hold.CaptureTransactionID = &entry.ID
+		if err := s.holdRepo.WithTx(tx).Update(hold); err != nil {
+			return err
+		}
+
+		result.Hold = hold
+		result.Transaction = entry
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *walletService) ReleaseHold(walletUserID, holdID string) (*models.Hold, error) {
+	var hold *models.Hold
+	err := s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		wallet, locked, err := s.lockHold(tx, walletUserID, holdID)
+		if err != nil {
+			return err
+		}
+		hold = locked
+		return s.releaseHold(tx, wallet, hold, models.HoldStatusReleased)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+func (s *walletService) ExpireHolds(now time.Time) (int, error) {
+	holds, err := s.holdRepo.ListExpired(now, expiredHoldBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, candidate := range holds {
+		err := s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+			wallet, hold, err := s.lockHold(tx, candidate.WalletUserID, candidate.ID)
+			if err != nil {
+				return err
+			}
+			// bisa saja sudah di-capture/release sejak di-list
+			if hold.ExpiresAt.After(now) {
+				return nil
+			}
+			if err := s.releaseHold(tx, wallet, hold, models.HoldStatusExpired); err != nil {
+				return err
+			}
+			expired++
+			return nil
+		})
+		if err != nil && utils.GetErrorCode(err) != utils.CodeHoldNotActive {
+			return expired, err
+		}
+	}
+	return expired, nil
+}
+
+// lockHold locks a wallet and then one of its active holds, in that order
+func (s *walletService) lockHold(tx *gorm.DB, walletUserID, holdID string) (*models.Wallet, *models.Hold, error) {
+	wallet, err := s.walletRepo.WithTx(tx).GetByWalletUserIDForUpdate(walletUserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hold, err := s.holdRepo.WithTx(tx).GetByIDForUpdate(holdID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if hold.WalletUserID != wallet.WalletUserID {
+		return nil, nil, utils.NewWalletError(utils.CodeHoldNotFound, "Hold not found", "")
+	}
+	if hold.Status != models.HoldStatusActive {
+		return nil, nil, utils.NewWalletError(utils.CodeHoldNotActive, "Hold is not active", fmt.Sprintf("status: %s", hold.Status))
+	}
+	return wallet, hold, nil
+}
+
+// releaseHold returns the full held amount to the available balance
+func (s *walletService) releaseHold(tx *gorm.DB, wallet *models.Wallet, hold *models.Hold, status string) error {
+	row, err := s.walletRepo.WithTx(tx).AdjustHeld(wallet.WalletUserID, hold.BalanceType, hold.Amount.Neg())
+	if err != nil {
+		return err
+	}
+	wallet.SetBalanceRow(*row)
+
+	hold.Status = status
+	return s.holdRepo.WithTx(tx).Update(hold)
+}
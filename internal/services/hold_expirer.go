@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// HoldExpirer periodically releases holds that are past their expiry time
+type HoldExpirer struct {
+	walletService WalletService
+	interval      time.Duration
+}
+
+// NewHoldExpirer creates a new hold expirer
+func NewHoldExpirer(walletService WalletService, interval time.Duration) *HoldExpirer {
+	return &HoldExpirer{
+		walletService: walletService,
+		interval:      interval,
+	}
+}
+
+// Run releases expired holds every interval until ctx is cancelled
+func (e *HoldExpirer) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := e.walletService.ExpireHolds(time.Now())
+			if err != nil {
+				log.Printf("[HoldExpirer] failed to expire holds: %v", err)
+			}
+			if expired > 0 {
+				log.Printf("[HoldExpirer] released %d expired holds", expired)
+			}
+		}
+	}
+}
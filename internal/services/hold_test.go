@@ -0,0 +1,107 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestPartialCaptureReturnsTheRest(t *testing.T) {
+	env := newTestEnv(t)
+	wallet := env.createWallet(t, map[string]string{"Coins": "10"})
+
+	hold, err := env.wallets.CreateHold(wallet.WalletUserID, &utils.CreateHoldRequest{BalanceType: "Coins", Amount: "6"})
+	if err != nil {
+		t.Fatalf("create hold: %v", err)
+	}
+
+	// saldo yang di-hold tidak bisa dipakai
+	_, err = env.wallets.DeductBalance(wallet.WalletUserID, &utils.UpdateBalanceRequest{BalanceType: "Coins", Amount: "5"})
+	assertErrorCode(t, err, utils.CodeInsufficientBalance)
+
+	result, err := env.wallets.CaptureHold(wallet.WalletUserID, hold.ID, &utils.CaptureHoldRequest{Amount: "4"})
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if result.Hold.Status != models.HoldStatusCaptured || !result.Transaction.Amount.Equal(decimal.NewFromInt(-4)) {
+		t.Errorf("got hold %s with entry %s, want captured with -4", result.Hold.Status, result.Transaction.Amount)
+	}
+
+	current, err := env.wallets.GetWallet(wallet.WalletUserID)
+	if err != nil {
+		t.Fatalf("get wallet: %v", err)
+	}
+	if !current.Balances["Coins"].Equal(decimal.NewFromInt(6)) || !current.Held["Coins"].IsZero() || !current.Available["Coins"].Equal(decimal.NewFromInt(6)) {
+		t.Errorf("balance %s, held %s, available %s; want 6, 0, 6", current.Balances["Coins"], current.Held["Coins"], current.Available["Coins"])
+	}
+
+	// hold yang sudah di-capture tidak bisa dipakai lagi
+	_, err = env.wallets.CaptureHold(wallet.WalletUserID, hold.ID, &utils.CaptureHoldRequest{})
+	assertErrorCode(t, err, utils.CodeHoldNotActive)
+	_, err = env.wallets.ReleaseHold(wallet.WalletUserID, hold.ID)
+	assertErrorCode(t, err, utils.CodeHoldNotActive)
+}
+
+func TestCaptureAboveHeldAmountIsRejected(t *testing.T) {
+	env := newTestEnv(t)
+	wallet := env.createWallet(t, map[string]string{"Coins": "10"})
+
+	hold, err := env.wallets.CreateHold(wallet.WalletUserID, &utils.CreateHoldRequest{BalanceType: "Coins", Amount: "3"})
+	if err != nil {
+		t.Fatalf("create hold: %v", err)
+	}
+	_, err = env.wallets.CaptureHold(wallet.WalletUserID, hold.ID, &utils.CaptureHoldRequest{Amount: "3.01"})
+	assertErrorCode(t, err, utils.CodeInvalidAmount)
+}
+
+func TestReleaseAndExpireHolds(t *testing.T) {
+	env := newTestEnv(t)
+	wallet := env.createWallet(t, map[string]string{"Coins": "10"})
+
+	released, err := env.wallets.CreateHold(wallet.WalletUserID, &utils.CreateHoldRequest{BalanceType: "Coins", Amount: "2"})
+	if err != nil {
+		t.Fatalf("create hold: %v", err)
+	}
+	expiring, err := env.wallets.CreateHold(wallet.WalletUserID, &utils.CreateHoldRequest{BalanceType: "Coins", Amount: "3", TTLSeconds: 1})
+	if err != nil {
+		t.Fatalf("create hold: %v", err)
+	}
+
+	hold, err := env.wallets.ReleaseHold(wallet.WalletUserID, released.ID)
+	if err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if hold.Status != models.HoldStatusReleased {
+		t.Errorf("released hold has status %s", hold.Status)
+	}
+
+	expired, err := env.wallets.ExpireHolds(time.Now().Add(2 * time.Second))
+	if err != nil {
+		t.Fatalf("expire holds: %v", err)
+	}
+	if expired != 1 {
+		t.Errorf("%d holds expired, want 1", expired)
+	}
+	hold, err = env.wallets.GetHold(wallet.WalletUserID, expiring.ID)
+	if err != nil {
+		t.Fatalf("get hold: %v", err)
+	}
+	if hold.Status != models.HoldStatusExpired {
+		t.Errorf("expired hold has status %s", hold.Status)
+	}
+
+	current, err := env.wallets.GetWallet(wallet.WalletUserID)
+	if err != nil {
+		t.Fatalf("get wallet: %v", err)
+	}
+	if !current.Balances["Coins"].Equal(decimal.NewFromInt(10)) || !current.Held["Coins"].IsZero() {
+		t.Errorf("balance %s, held %s; want 10, 0", current.Balances["Coins"], current.Held["Coins"])
+	}
+	if debits := env.countTransactions(t, wallet.WalletUserID, "amount < 0"); debits != 0 {
+		t.Errorf("%d debit entries after releasing holds, want 0", debits)
+	}
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"testing"
+
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestIdempotentReplayReturnsOriginalResponse(t *testing.T) {
+	env := newTestEnv(t)
+	wallet := env.createWallet(t, map[string]string{"Coins": "10"})
+
+	req := &utils.UpdateBalanceRequest{BalanceType: "Coins", Amount: "4", IdempotencyKey: "order-1"}
+	first, err := env.wallets.DeductBalance(wallet.WalletUserID, req)
+	if err != nil {
+		t.Fatalf("deduct: %v", err)
+	}
+
+	// saldo berubah setelah request pertama
+	if _, err := env.wallets.AddBalance(wallet.WalletUserID, &utils.UpdateBalanceRequest{BalanceType: "Coins", Amount: "100"}); err != nil {
+		t.Fatalf("add balance: %v", err)
+	}
+
+	replay, err := env.wallets.DeductBalance(wallet.WalletUserID, req)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if !replay.Replayed {
+		t.Error("replay is not marked as replayed")
+	}
+	if replay.Transaction.ID != first.Transaction.ID {
+		t.Errorf("replay returned transaction %s, want %s", replay.Transaction.ID, first.Transaction.ID)
+	}
+	if !replay.Balances["Coins"].Equal(first.Balances["Coins"]) {
+		t.Errorf("replay reports balance %s, the original request got %s", replay.Balances["Coins"], first.Balances["Coins"])
+	}
+
+	if got := env.balance(t, wallet.WalletUserID, "Coins"); !got.Equal(decimal.NewFromInt(106)) {
+		t.Errorf("balance = %s, want 106: the replay must not deduct again", got)
+	}
+	if debits := env.countTransactions(t, wallet.WalletUserID, "amount < 0"); debits != 1 {
+		t.Errorf("%d debit entries, want 1", debits)
+	}
+}
+
+func TestIdempotencyKeyReusedWithDifferentRequest(t *testing.T) {
+	env := newTestEnv(t)
+	wallet := env.createWallet(t, map[string]string{"Coins": "10"})
+
+	if _, err := env.wallets.DeductBalance(wallet.WalletUserID, &utils.UpdateBalanceRequest{BalanceType: "Coins", Amount: "4", IdempotencyKey: "order-1"}); err != nil {
+		t.Fatalf("deduct: %v", err)
+	}
+
+	for name, req := range map[string]*utils.UpdateBalanceRequest{
+		"amount":    {BalanceType: "Coins", Amount: "5", IdempotencyKey: "order-1"},
+		"reference": {BalanceType: "Coins", Amount: "4", Reference: "other", IdempotencyKey: "order-1"},
+	} {
+		_, err := env.wallets.DeductBalance(wallet.WalletUserID, req)
+		if err == nil {
+			t.Errorf("%s: reuse of the key was accepted", name)
+			continue
+		}
+		assertErrorCode(t, err, utils.CodeIdempotencyConflict)
+	}
+
+	// operasi lain dengan key yang sama juga konflik
+	_, err := env.wallets.AddBalance(wallet.WalletUserID, &utils.UpdateBalanceRequest{BalanceType: "Coins", Amount: "4", IdempotencyKey: "order-1"})
+	assertErrorCode(t, err, utils.CodeIdempotencyConflict)
+}
+
+func TestIdempotentTransferReplay(t *testing.T) {
+	env := newTestEnv(t)
+	source := env.createWallet(t, map[string]string{"Coins": "10"})
+	destination := env.createWallet(t, nil)
+
+	req := &utils.TransferRequest{
+		FromWalletUserID: source.WalletUserID,
+		ToWalletUserID:   destination.WalletUserID,
+		BalanceType:      "Coins",
+		Amount:           "2.50",
+		IdempotencyKey:   "transfer-1",
+	}
+	first, err := env.wallets.Transfer(req)
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	replay, err := env.wallets.Transfer(req)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if !replay.Replayed || replay.TransferID != first.TransferID {
+		t.Errorf("replay returned transfer %s (replayed %v), want %s", replay.TransferID, replay.Replayed, first.TransferID)
+	}
+	if got := env.balance(t, destination.WalletUserID, "Coins"); !got.Equal(decimal.RequireFromString("2.5")) {
+		t.Errorf("destination balance = %s, want 2.5", got)
+	}
+}
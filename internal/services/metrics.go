@@ -0,0 +1,33 @@
+package services
+
+import (
+	"e-commerce_marketplace/internal/metrics"
+	"e-commerce_marketplace/pkg/utils"
+)
+
+// recordBalanceOperation records the outcome of a credit, debit or transfer.
+// Balance types that were never validated are reported as "unknown" so
+// arbitrary input cannot create new metric series.
+func recordBalanceOperation(operation, balanceType string, replayed bool, err error) {
+	if err == nil {
+		outcome := metrics.OutcomeSuccess
+		if replayed {
+			outcome = metrics.OutcomeReplayed
+		}
+		metrics.RecordBalanceOperation(operation, balanceType, outcome)
+		return
+	}
+
+	code := utils.GetErrorCode(err)
+	switch code {
+	case utils.CodeValidationError, utils.CodeInvalidBalanceType, utils.CodeBalanceTypeUnavailable:
+		balanceType = "unknown"
+	}
+	switch code {
+	case utils.CodeDatabaseError, utils.CodeInternalError:
+		metrics.RecordBalanceOperation(operation, balanceType, metrics.OutcomeError)
+	default:
+		metrics.RecordBalanceOperation(operation, balanceType, metrics.OutcomeRejected)
+		metrics.RecordBalanceRejection(operation, code)
+	}
+}
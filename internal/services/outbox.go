@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/internal/repositories"
+	"e-commerce_marketplace/pkg/utils"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// EventSink receives events drained from the outbox. Delivery is
+// at-least-once, so Send may be called again with an event it already got.
+type EventSink interface {
+	Name() string
+	Send(ctx context.Context, event *models.Event) error
+}
+
+type outboxPublisher struct {
+	outboxRepo repositories.OutboxRepository
+}
+
+// NewOutboxPublisher creates an event publisher that writes events to the
+// outbox table in the caller's transaction
+func NewOutboxPublisher(outboxRepo repositories.OutboxRepository) EventPublisher {
+	return &outboxPublisher{outboxRepo: outboxRepo}
+}
+
+func (p *outboxPublisher) Publish(tx *gorm.DB, event *models.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return utils.NewWalletError(utils.CodeInternalError, "Failed to encode event", err.Error())
+	}
+
+	return p.outboxRepo.WithTx(tx).Create(&models.OutboxEvent{
+		EventID:       event.ID,
+		Type:          event.Type,
+		WalletUserID:  event.WalletUserID,
+		Payload:       datatypes.JSON(payload),
+		NextAttemptAt: event.CreatedAt,
+	})
+}
+
+// OutboxDispatcherConfig configures how the outbox is drained
+type OutboxDispatcherConfig struct {
+	// Interval between polls of the outbox
+	Interval time.Duration
+
+	// BatchSize is the maximum number of events claimed per poll
+	BatchSize int
+
+	// Lease is how long claimed events are hidden from other dispatchers.
+	// Events still unsent when it runs out are claimed again.
+	Lease time.Duration
+
+	// Retry backoff after a sink failure starts at BaseBackoff, doubles per
+	// attempt and is capped at MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+
+	// Retention is how long published events are kept
+	Retention time.Duration
+}
+
+// DefaultOutboxDispatcherConfig returns the default outbox settings
+func DefaultOutboxDispatcherConfig() OutboxDispatcherConfig {
+	return OutboxDispatcherConfig{
+		Interval:    time.Second,
+		BatchSize:   100,
+		Lease:       time.Minute,
+		BaseBackoff: time.Second,
+		MaxBackoff:  5 * time.Minute,
+		Retention:   7 * 24 * time.Hour,
+	}
+}
+
+// OutboxDispatcher drains the outbox to the configured sinks. Events are
+// claimed in a short transaction and sent outside of it, so dispatchers on
+// several instances can work side by side. Events of one wallet are sent in
+// outbox order, and an event that fails holds back the later events of the
+// same wallet until it succeeds.
+type OutboxDispatcher struct {
+	outboxRepo repositories.OutboxRepository
+	sinks      []EventSink
+	config     OutboxDispatcherConfig
+}
+
+// NewOutboxDispatcher creates a new outbox dispatcher
+func NewOutboxDispatcher(outboxRepo repositories.OutboxRepository, sinks []EventSink, config OutboxDispatcherConfig) *OutboxDispatcher {
+	return &OutboxDispatcher{
+		outboxRepo: outboxRepo,
+		sinks:      sinks,
+		config:     config,
+	}
+}
+
+// Run drains the outbox every interval and prunes published events every
+// hour until ctx is cancelled
+func (d *OutboxDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.Interval)
+	defer ticker.Stop()
+	pruneTicker := time.NewTicker(time.Hour)
+	defer pruneTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.dispatch(ctx); err != nil {
+				log.Printf("[OutboxDispatcher] failed to drain outbox: %v", err)
+			}
+		case <-pruneTicker.C:
+			pruned, err := d.outboxRepo.DeletePublishedBefore(time.Now().Add(-d.config.Retention))
+			if err != nil {
+				log.Printf("[OutboxDispatcher] failed to prune outbox: %v", err)
+			}
+			if pruned > 0 {
+				log.Printf("[OutboxDispatcher] pruned %d published events", pruned)
+			}
+		}
+	}
+}
+
+// dispatch claims one batch of due events and sends them. Events that are
+// not sent, because an earlier event of their wallet failed or the
+// dispatcher is stopping, are handed back right away instead of waiting for
+// their lease to run out.
+func (d *OutboxDispatcher) dispatch(ctx context.Context) error {
+	events, err := d.outboxRepo.ClaimDue(time.Now(), d.config.Lease, d.config.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	failed := make(map[string]bool)
+	unsent := make([]int64, 0)
+	for i := range events {
+		row := &events[i]
+
+		// urutan per wallet: event berikutnya menunggu event yang gagal
+		if ctx.Err() != nil || failed[row.WalletUserID] {
+			unsent = append(unsent, row.ID)
+			continue
+		}
+
+		if err := d.send(ctx, row); err != nil {
+			failed[row.WalletUserID] = true
+			row.Attempts++
+			row.LastError = err.Error()
+			row.NextAttemptAt = time.Now().Add(d.backoff(row.Attempts))
+			if err := d.outboxRepo.Update(row); err != nil {
+				return err
+			}
+			log.Printf("[OutboxDispatcher] event %s (%s) failed, attempt %d: %v", row.EventID, row.Type, row.Attempts, err)
+			continue
+		}
+		if err := d.outboxRepo.MarkPublished([]int64{row.ID}, time.Now()); err != nil {
+			return err
+		}
+	}
+	return d.outboxRepo.Reschedule(unsent, time.Now())
+}
+
+// send hands one event to every sink
+func (d *OutboxDispatcher) send(ctx context.Context, row *models.OutboxEvent) error {
+	event, err := row.Event()
+	if err != nil {
+		return fmt.Errorf("decode event: %w", err)
+	}
+	for _, sink := range d.sinks {
+		if err := sink.Send(ctx, event); err != nil {
+			return fmt.Errorf("%s sink: %w", sink.Name(), err)
+		}
+	}
+	return nil
+}
+
+// backoff returns the delay before the next attempt of a failed event
+func (d *OutboxDispatcher) backoff(attempts int) time.Duration {
+	delay := d.config.BaseBackoff << (attempts - 1)
+	if delay <= 0 || delay > d.config.MaxBackoff {
+		delay = d.config.MaxBackoff
+	}
+	return delay
+}
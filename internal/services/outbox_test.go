@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/internal/repositories"
+	"e-commerce_marketplace/internal/testdb"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// recordingSink remembers the events it got and fails those of the wallets
+// in failing
+type recordingSink struct {
+	mu      sync.Mutex
+	failing map[string]bool
+	sent    []string
+}
+
+func (s *recordingSink) Name() string {
+	return "recording"
+}
+
+func (s *recordingSink) Send(ctx context.Context, event *models.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failing[event.WalletUserID] {
+		return errors.New("sink unavailable")
+	}
+	s.sent = append(s.sent, event.ID)
+	return nil
+}
+
+func publishTestEvents(t *testing.T, db *gorm.DB, outboxRepo repositories.OutboxRepository, walletUserIDs ...string) []string {
+	t.Helper()
+
+	publisher := NewOutboxPublisher(outboxRepo)
+	ids := make([]string, len(walletUserIDs))
+	for i, walletUserID := range walletUserIDs {
+		ids[i] = uuid.New().String()
+		err := publisher.Publish(db, &models.Event{
+			ID:           ids[i],
+			Type:         "wallet.balance.credited",
+			WalletUserID: walletUserID,
+			Data:         []byte(`{}`),
+			CreatedAt:    time.Now().Add(-time.Second),
+		})
+		if err != nil {
+			t.Fatalf("publish event: %v", err)
+		}
+	}
+	return ids
+}
+
+func TestOutboxFailedEventHoldsBackItsWallet(t *testing.T) {
+	db := testdb.Open(t)
+	outboxRepo := repositories.NewOutboxRepository(db)
+	events := publishTestEvents(t, db, outboxRepo, "wallet-a", "wallet-b", "wallet-a", "wallet-b")
+
+	sink := &recordingSink{failing: map[string]bool{"wallet-a": true}}
+	dispatcher := NewOutboxDispatcher(outboxRepo, []EventSink{sink}, DefaultOutboxDispatcherConfig())
+	if err := dispatcher.dispatch(context.Background()); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if len(sink.sent) != 2 || sink.sent[0] != events[1] || sink.sent[1] != events[3] {
+		t.Fatalf("sent %v, want the events of wallet-b in order", sink.sent)
+	}
+
+	var rows []models.OutboxEvent
+	if err := db.Order("id").Find(&rows).Error; err != nil {
+		t.Fatalf("read outbox: %v", err)
+	}
+	if rows[0].Attempts != 1 || rows[0].LastError == "" || !rows[0].NextAttemptAt.After(time.Now()) {
+		t.Errorf("failed event has %d attempts, error %q, next attempt %s", rows[0].Attempts, rows[0].LastError, rows[0].NextAttemptAt)
+	}
+	// event berikutnya dikembalikan, tidak menunggu lease habis
+	if rows[2].Attempts != 0 || rows[2].PublishedAt != nil || rows[2].NextAttemptAt.After(time.Now()) {
+		t.Errorf("held back event has %d attempts and next attempt %s", rows[2].Attempts, rows[2].NextAttemptAt)
+	}
+
+	// selama event pertama menunggu retry, event kedua tidak boleh diklaim
+	claimed, err := outboxRepo.ClaimDue(time.Now(), time.Minute, 100)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if len(claimed) != 0 {
+		t.Errorf("claimed %d events of a wallet waiting for a retry", len(claimed))
+	}
+
+	sink.failing = nil
+	if err := db.Model(&models.OutboxEvent{}).Where("id = ?", rows[0].ID).Update("next_attempt_at", time.Now()).Error; err != nil {
+		t.Fatalf("make event due: %v", err)
+	}
+	if err := dispatcher.dispatch(context.Background()); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if len(sink.sent) != 4 || sink.sent[2] != events[0] || sink.sent[3] != events[2] {
+		t.Errorf("sent %v, want the events of wallet-a in order after the retry", sink.sent)
+	}
+}
+
+func TestOutboxClaimedEventsAreLeased(t *testing.T) {
+	db := testdb.Open(t)
+	outboxRepo := repositories.NewOutboxRepository(db)
+	publishTestEvents(t, db, outboxRepo, "wallet-a", "wallet-a", "wallet-b")
+
+	first, err := outboxRepo.ClaimDue(time.Now(), time.Minute, 2)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if len(first) != 2 || first[0].WalletUserID != "wallet-a" || first[1].WalletUserID != "wallet-a" {
+		t.Fatalf("first claim got %+v", first)
+	}
+
+	// dispatcher lain hanya mendapat wallet yang belum diklaim
+	second, err := outboxRepo.ClaimDue(time.Now(), time.Minute, 100)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if len(second) != 1 || second[0].WalletUserID != "wallet-b" {
+		t.Fatalf("second claim got %+v", second)
+	}
+
+	// lease habis: event diklaim lagi
+	again, err := outboxRepo.ClaimDue(time.Now().Add(2*time.Minute), time.Minute, 100)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if len(again) != 3 {
+		t.Errorf("claimed %d events after the lease, want 3", len(again))
+	}
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"fmt"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+func (s *walletService) ReverseTransaction(transactionID string, req *utils.ReverseTransactionRequest) (*models.BalanceUpdateResult, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, utils.NewWalletError(utils.CodeValidationError, "Validation failed", fmt.Sprintf("%s: %s", validationErrors[0].Field, validationErrors[0].Message))
+	}
+
+	original, err := s.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if !original.Amount.IsNegative() || original.ReversalOf != nil {
+		return nil, utils.NewWalletError(utils.CodeTransactionNotReversible, "Only debits can be reversed", "")
+	}
+	if original.TransferID != nil {
+		return nil, utils.NewWalletError(utils.CodeTransactionNotReversible, "Transfer legs cannot be reversed", "create a transfer in the opposite direction instead")
+	}
+
+	result := &models.BalanceUpdateResult{}
+	err = s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		// lock wallet supaya reversal paralel tidak melebihi jumlah asli
+		wallet, err := s.walletRepo.WithTx(tx).GetByWalletUserIDForUpdate(original.WalletUserID)
+		if err != nil {
+			return err
+		}
+
+		reversed, err := s.transactionRepo.WithTx(tx).SumReversals(original.ID)
+		if err != nil {
+			return err
+		}
+		remaining := original.Amount.Neg().Sub(reversed)
+
+		// tanpa amount berarti refund sisa penuh; amount nol di hash menandai "penuh"
+		amount := remaining
+		requested := decimal.Zero
+		if req.Amount != "" {
+			amount, err = s.parseScaledAmount(original.BalanceType, req.Amount)
+			if err != nil {
+				return err
+			}
+			requested = amount
+		}
+
+		hash := requestHash(operationReversal, original.BalanceType, requested, req.Reference, req.Metadata, original.ID)
+		replayed, err := s.findIdempotentReplay(tx, wallet.WalletUserID, req.IdempotencyKey, hash)
+		if err != nil {
+			return err
+		}
+		if replayed != nil {
+			result.Transaction = replayed
+			result.Replayed = true
+			return nil
+		}
+
+		if !remaining.IsPositive() {
+			return utils.NewWalletError(utils.CodeTransactionNotReversible, "Transaction has already been fully reversed", "")
+		}
+		if amount.GreaterThan(remaining) {
+			return utils.NewWalletError(
+				utils.CodeReversalExceedsOriginal,
+				"Reversal exceeds the remaining amount of the original transaction",
+				fmt.Sprintf("original: %s, already reversed: %s, requested: %s", original.Amount.Neg(), reversed, amount),
+			)
+		}
+
+		reference := req.Reference
+		if reference == "" {
+			reference = original.Reference
+		}
+		entry, err := newLedgerEntry(original.BalanceType, amount, reference, req.Metadata)
+		if err != nil {
+			return err
+		}
+		entry.ReversalOf = &original.ID
+		if req.IdempotencyKey != "" {
+			entry.IdempotencyKey = &req.IdempotencyKey
+			entry.RequestHash = hash
+		}
+
+		if err := s.applyEntry(tx, wallet, entry); err != nil {
+			return err
+		}
+		result.Transaction = entry
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Wallet, err = s.walletRepo.GetByWalletUserID(original.WalletUserID); err != nil {
+		return nil, err
+	}
+	if result.Replayed {
+		replayBalance(result.Wallet, result.Transaction)
+	}
+	return result, nil
+}
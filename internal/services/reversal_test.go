@@ -0,0 +1,72 @@
+package services
+
+import (
+	"testing"
+
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestReversalsAreCappedAtTheOriginalDebit(t *testing.T) {
+	env := newTestEnv(t)
+	wallet := env.createWallet(t, map[string]string{"Coins": "10"})
+
+	debit, err := env.wallets.DeductBalance(wallet.WalletUserID, &utils.UpdateBalanceRequest{BalanceType: "Coins", Amount: "6", Reference: "order-1"})
+	if err != nil {
+		t.Fatalf("deduct: %v", err)
+	}
+	debitID := debit.Transaction.ID
+
+	partial, err := env.wallets.ReverseTransaction(debitID, &utils.ReverseTransactionRequest{Amount: "2.50"})
+	if err != nil {
+		t.Fatalf("partial reversal: %v", err)
+	}
+	if partial.Transaction.ReversalOf == nil || *partial.Transaction.ReversalOf != debitID || partial.Transaction.Reference != "order-1" {
+		t.Errorf("reversal is not linked to the debit: %+v", partial.Transaction)
+	}
+
+	_, err = env.wallets.ReverseTransaction(debitID, &utils.ReverseTransactionRequest{Amount: "3.51"})
+	assertErrorCode(t, err, utils.CodeReversalExceedsOriginal)
+
+	// tanpa amount: refund sisa penuh
+	rest, err := env.wallets.ReverseTransaction(debitID, &utils.ReverseTransactionRequest{})
+	if err != nil {
+		t.Fatalf("full reversal: %v", err)
+	}
+	if !rest.Transaction.Amount.Equal(decimal.RequireFromString("3.5")) {
+		t.Errorf("remaining reversal = %s, want 3.5", rest.Transaction.Amount)
+	}
+
+	_, err = env.wallets.ReverseTransaction(debitID, &utils.ReverseTransactionRequest{Amount: "0.01"})
+	assertErrorCode(t, err, utils.CodeTransactionNotReversible)
+
+	if got := env.balance(t, wallet.WalletUserID, "Coins"); !got.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("balance = %s, want 10", got)
+	}
+}
+
+func TestOnlyDebitsCanBeReversed(t *testing.T) {
+	env := newTestEnv(t)
+	wallet := env.createWallet(t, nil)
+
+	credit, err := env.wallets.AddBalance(wallet.WalletUserID, &utils.UpdateBalanceRequest{BalanceType: "Coins", Amount: "5"})
+	if err != nil {
+		t.Fatalf("add balance: %v", err)
+	}
+	_, err = env.wallets.ReverseTransaction(credit.Transaction.ID, &utils.ReverseTransactionRequest{})
+	assertErrorCode(t, err, utils.CodeTransactionNotReversible)
+
+	other := env.createWallet(t, nil)
+	transfer, err := env.wallets.Transfer(&utils.TransferRequest{
+		FromWalletUserID: wallet.WalletUserID,
+		ToWalletUserID:   other.WalletUserID,
+		BalanceType:      "Coins",
+		Amount:           "1",
+	})
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	_, err = env.wallets.ReverseTransaction(transfer.Debit.ID, &utils.ReverseTransactionRequest{})
+	assertErrorCode(t, err, utils.CodeTransactionNotReversible)
+}
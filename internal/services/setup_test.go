@@ -0,0 +1,109 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/internal/repositories"
+	"e-commerce_marketplace/internal/testdb"
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// testEnv is a wallet service on a migrated test schema with the balance
+// types Coins (2 decimals, transferable) and Exp (whole numbers, not
+// transferable) in a local registry
+type testEnv struct {
+	db       *gorm.DB
+	wallets  *walletService
+	provider *CachedBalanceTypeProvider
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	db := testdb.Open(t)
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("access connection pool: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(20)
+
+	balanceTypeRepo := repositories.NewBalanceTypeRepository(db)
+	for _, balanceType := range []models.BalanceType{
+		{Name: "Coins", Scale: 2, Transferable: true, Withdrawable: true},
+		{Name: "Exp", Scale: 0},
+	} {
+		balanceType := balanceType
+		if err := balanceTypeRepo.Create(&balanceType); err != nil {
+			t.Fatalf("create balance type %s: %v", balanceType.Name, err)
+		}
+	}
+	provider := NewBalanceTypeProvider(NewLocalBalanceTypeRegistry(balanceTypeRepo), DefaultBalanceTypeProviderConfig())
+
+	wallets := NewWalletService(
+		repositories.NewWalletRepository(db),
+		repositories.NewTransactionRepository(db),
+		repositories.NewHoldRepository(db),
+		repositories.NewTxManager(db),
+		provider,
+		NewOutboxPublisher(repositories.NewOutboxRepository(db)),
+		15*time.Minute,
+	).(*walletService)
+
+	return &testEnv{db: db, wallets: wallets, provider: provider}
+}
+
+// createWallet creates a wallet with a random wallet user ID and the given
+// initial balances
+func (e *testEnv) createWallet(t *testing.T, initialBalances map[string]string) *models.Wallet {
+	t.Helper()
+
+	wallet, err := e.wallets.CreateWallet(&utils.CreateWalletRequest{
+		WalletUserID:    "user-" + uuid.New().String(),
+		InitialBalances: initialBalances,
+	})
+	if err != nil {
+		t.Fatalf("create wallet: %v", err)
+	}
+	return wallet
+}
+
+// balance returns the stored amount of one balance type of a wallet
+func (e *testEnv) balance(t *testing.T, walletUserID, balanceType string) decimal.Decimal {
+	t.Helper()
+
+	wallet, err := e.wallets.GetWallet(walletUserID)
+	if err != nil {
+		t.Fatalf("get wallet: %v", err)
+	}
+	return wallet.Balances[balanceType]
+}
+
+// countTransactions counts the ledger entries of a wallet matching the condition
+func (e *testEnv) countTransactions(t *testing.T, walletUserID, condition string) int64 {
+	t.Helper()
+
+	var count int64
+	if err := e.db.Model(&models.Transaction{}).Where("wallet_user_id = ?", walletUserID).Where(condition).Count(&count).Error; err != nil {
+		t.Fatalf("count transactions: %v", err)
+	}
+	return count
+}
+
+// assertErrorCode fails the test unless err is a WalletError with the code
+func assertErrorCode(t *testing.T, err error, code string) {
+	t.Helper()
+
+	walletErr, ok := err.(*utils.WalletError)
+	if !ok {
+		t.Fatalf("got error %v, want %s", err, code)
+	}
+	if walletErr.Code != code {
+		t.Fatalf("got error code %s (%s), want %s", walletErr.Code, walletErr.Message, code)
+	}
+}
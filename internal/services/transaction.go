@@ -0,0 +1,171 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/internal/repositories"
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// defaultTransactionPageSize is used when no limit is given when listing transactions
+const defaultTransactionPageSize = 20
+
+func (s *walletService) ListTransactions(walletUserID string, req *utils.ListTransactionsRequest) (*models.TransactionPage, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, utils.NewWalletError(utils.CodeValidationError, "Validation failed", fmt.Sprintf("%s: %s", validationErrors[0].Field, validationErrors[0].Message))
+	}
+
+	filter := repositories.TransactionFilter{
+		BalanceType: req.BalanceType,
+		Direction:   req.Direction,
+		Reference:   req.Reference,
+		Limit:       req.Limit,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultTransactionPageSize
+	}
+
+	if req.From != "" {
+		from, err := time.Parse(time.RFC3339, req.From)
+		if err != nil {
+			return nil, utils.NewWalletError(utils.CodeValidationError, "from must be an RFC3339 timestamp", err.Error())
+		}
+		filter.From = &from
+	}
+	if req.To != "" {
+		to, err := time.Parse(time.RFC3339, req.To)
+		if err != nil {
+			return nil, utils.NewWalletError(utils.CodeValidationError, "to must be an RFC3339 timestamp", err.Error())
+		}
+		filter.To = &to
+	}
+	if req.Cursor != "" {
+		createdAt, id, err := utils.DecodeCursor(req.Cursor)
+		if err != nil {
+			return nil, utils.NewWalletError(utils.CodeValidationError, "Invalid cursor", "")
+		}
+		filter.AfterCreatedAt = &createdAt
+		filter.AfterID = id
+	}
+
+	// request divalidasi dulu sebelum menyentuh database
+	wallet, err := s.walletRepo.GetByWalletUserID(walletUserID)
+	if err != nil {
+		return nil, err
+	}
+	filter.WalletUserID = wallet.WalletUserID
+
+	// ambil satu entry lebih untuk tahu apakah masih ada halaman berikutnya
+	limit := filter.Limit
+	filter.Limit = limit + 1
+	transactions, err := s.transactionRepo.List(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.TransactionPage{Transactions: transactions}
+	if len(transactions) > limit {
+		page.Transactions = transactions[:limit]
+		page.HasMore = true
+		last := page.Transactions[limit-1]
+		page.NextCursor = utils.EncodeCursor(last.CreatedAt, last.ID)
+	}
+	if page.Transactions == nil {
+		page.Transactions = []models.Transaction{}
+	}
+	return page, nil
+}
+
+// newLedgerEntry builds an unsaved ledger entry with a signed amount
+func newLedgerEntry(balanceType string, amount decimal.Decimal, reference string, metadata map[string]interface{}) (*models.Transaction, error) {
+	data, err := marshalMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Transaction{
+		BalanceType: balanceType,
+		Amount:      amount,
+		Reference:   reference,
+		Metadata:    data,
+	}, nil
+}
+
+// marshalMetadata encodes caller-supplied metadata for storage
+func marshalMetadata(metadata map[string]interface{}) (datatypes.JSON, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, utils.NewWalletError(utils.CodeValidationError, "Invalid metadata", err.Error())
+	}
+	return datatypes.JSON(data), nil
+}
+
+// findIdempotentReplay returns the ledger entry previously written for a
+// wallet under the idempotency key, or nil if the key has not been used yet.
+// Reusing a key for a request with a different hash is rejected.
+func (s *walletService) findIdempotentReplay(tx *gorm.DB, walletUserID, key, hash string) (*models.Transaction, error) {
+	if key == "" {
+		return nil, nil
+	}
+
+	original, err := s.transactionRepo.WithTx(tx).FindByIdempotencyKey(walletUserID, key)
+	if err != nil || original == nil {
+		return nil, err
+	}
+
+	if original.RequestHash != hash {
+		return nil, utils.NewWalletError(
+			utils.CodeIdempotencyConflict,
+			"Idempotency key was already used with a different request",
+			fmt.Sprintf("idempotency key %q belongs to transaction %s", key, original.ID),
+		)
+	}
+	return original, nil
+}
+
+// replayBalance makes a replayed credit or debit answer like the original
+// request did: the balance of the replayed entry's type is reported as it was
+// right after that entry (its balance_after), not as it is now. Holds are
+// not recorded in the ledger, so the held part is the current one.
+func replayBalance(wallet *models.Wallet, original *models.Transaction) {
+	wallet.SetBalanceRow(models.WalletBalance{
+		BalanceType: original.BalanceType,
+		Amount:      original.BalanceAfter,
+		Held:        wallet.Held[original.BalanceType],
+	})
+}
+
+// requestHash fingerprints a balance request so replays can be told apart
+// from conflicting reuse of the same idempotency key. parties holds the
+// wallets involved beyond the one the key is stored on (e.g. a transfer target).
+func requestHash(operation, balanceType string, amount decimal.Decimal, reference string, metadata map[string]interface{}, parties ...string) string {
+	payload, _ := json.Marshal(struct {
+		Operation string                 `json:"operation"`
+		Type      string                 `json:"type"`
+		Amount    string                 `json:"amount"`
+		Reference string                 `json:"reference"`
+		Metadata  map[string]interface{} `json:"metadata"`
+		Parties   []string               `json:"parties,omitempty"`
+	}{
+		Operation: operation,
+		Type:      balanceType,
+		Amount:    amount.String(),
+		Reference: reference,
+		Metadata:  metadata,
+		Parties:   parties,
+	})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"e-commerce_marketplace/pkg/utils"
+)
+
+func TestListTransactionsPaginates(t *testing.T) {
+	env := newTestEnv(t)
+	wallet := env.createWallet(t, nil)
+
+	for i := 0; i < 5; i++ {
+		if _, err := env.wallets.AddBalance(wallet.WalletUserID, &utils.UpdateBalanceRequest{BalanceType: "Coins", Amount: "1"}); err != nil {
+			t.Fatalf("add balance: %v", err)
+		}
+	}
+
+	seen := make(map[string]bool)
+	req := &utils.ListTransactionsRequest{Limit: 2}
+	var last time.Time
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("pagination did not end")
+		}
+		page, err := env.wallets.ListTransactions(wallet.WalletUserID, req)
+		if err != nil {
+			t.Fatalf("list transactions: %v", err)
+		}
+		for _, transaction := range page.Transactions {
+			if seen[transaction.ID] {
+				t.Fatalf("transaction %s returned twice", transaction.ID)
+			}
+			if !last.IsZero() && transaction.CreatedAt.After(last) {
+				t.Fatalf("transactions are not ordered newest first")
+			}
+			seen[transaction.ID] = true
+			last = transaction.CreatedAt
+		}
+		if !page.HasMore {
+			if page.NextCursor != "" {
+				t.Error("last page has a cursor")
+			}
+			break
+		}
+		req.Cursor = page.NextCursor
+	}
+	if len(seen) != 5 {
+		t.Errorf("got %d transactions, want 5", len(seen))
+	}
+}
+
+func TestListTransactionsRejectsBadCursor(t *testing.T) {
+	env := newTestEnv(t)
+	wallet := env.createWallet(t, nil)
+
+	for _, cursor := range []string{
+		"not a cursor",
+		utils.EncodeCursor(time.Now(), "not-a-uuid"),
+	} {
+		_, err := env.wallets.ListTransactions(wallet.WalletUserID, &utils.ListTransactionsRequest{Cursor: cursor})
+		assertErrorCode(t, err, utils.CodeValidationError)
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"fmt"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/internal/repositories"
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (s *walletService) Transfer(req *utils.TransferRequest) (*models.TransferResult, error) {
+	result, err := s.transfer(req)
+	recordBalanceOperation(operationTransfer, req.BalanceType, result != nil && result.Replayed, err)
+	return result, err
+}
+
+// transfer moves balance from one wallet to another in a single transaction
+func (s *walletService) transfer(req *utils.TransferRequest) (*models.TransferResult, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, utils.NewWalletError(utils.CodeValidationError, "Validation failed", "")
+	}
+	if req.FromWalletUserID == req.ToWalletUserID {
+		return nil, utils.NewWalletError(utils.CodeValidationError, "Source and destination wallets must be different", "")
+	}
+
+	amount, balanceType, err := s.validateAmount(req.BalanceType, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	if !balanceType.Transferable {
+		return nil, utils.NewWalletError(utils.CodeBalanceTypeNotTransferable, fmt.Sprintf("%s cannot be transferred between wallets", balanceType.Name), "")
+	}
+
+	result := &models.TransferResult{}
+	err = s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		source, destination, err := lockWalletPair(s.walletRepo.WithTx(tx), req.FromWalletUserID, req.ToWalletUserID)
+		if err != nil {
+			return err
+		}
+
+		// idempotency key disimpan di leg debit (wallet sumber)
+		hash := requestHash(operationTransfer, req.BalanceType, amount, req.Reference, req.Metadata, destination.WalletUserID)
+		original, err := s.findIdempotentReplay(tx, source.WalletUserID, req.IdempotencyKey, hash)
+		if err != nil {
+			return err
+		}
+		if original != nil && original.TransferID != nil {
+			legs, err := s.transactionRepo.WithTx(tx).ListByTransferID(*original.TransferID)
+			if err != nil {
+				return err
+			}
+			result.TransferID = *original.TransferID
+			for i := range legs {
+				if legs[i].Amount.IsNegative() {
+					result.Debit = &legs[i]
+				} else {
+					result.Credit = &legs[i]
+				}
+			}
+			result.Replayed = true
+			return nil
+		}
+
+		transferID := uuid.New().String()
+		debit, err := newLedgerEntry(req.BalanceType, amount.Neg(), req.Reference, req.Metadata)
+		if err != nil {
+			return err
+		}
+		debit.TransferID = &transferID
+		if req.IdempotencyKey != "" {
+			debit.IdempotencyKey = &req.IdempotencyKey
+			debit.RequestHash = hash
+		}
+
+		credit, err := newLedgerEntry(req.BalanceType, amount, req.Reference, req.Metadata)
+		if err != nil {
+			return err
+		}
+		credit.TransferID = &transferID
+
+		if err := s.applyEntry(tx, source, debit); err != nil {
+			return err
+		}
+		if err := s.applyEntry(tx, destination, credit); err != nil {
+			return err
+		}
+
+		result.TransferID = transferID
+		result.Debit = debit
+		result.Credit = credit
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// lockWalletPair locks both wallets of a transfer. Either side may be given
+// by wallet ID or wallet user ID, so both are resolved first and then locked
+// by their wallet ID, always in sorted order so two opposite transfers cannot
+// deadlock.
+func lockWalletPair(walletRepo repositories.WalletRepository, fromID, toID string) (*models.Wallet, *models.Wallet, error) {
+	from, err := walletRepo.GetByWalletUserID(fromID)
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := walletRepo.GetByWalletUserID(toID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if from.ID == to.ID {
+		return nil, nil, utils.NewWalletError(utils.CodeValidationError, "Source and destination wallets must be different", "")
+	}
+
+	firstID, secondID := from.ID, to.ID
+	if secondID < firstID {
+		firstID, secondID = secondID, firstID
+	}
+
+	first, err := walletRepo.GetByWalletUserIDForUpdate(firstID)
+	if err != nil {
+		return nil, nil, err
+	}
+	second, err := walletRepo.GetByWalletUserIDForUpdate(secondID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if firstID == from.ID {
+		return first, second, nil
+	}
+	return second, first, nil
+}
@@ -2,32 +2,87 @@ package services
 
 import (
 	"fmt"
-	"strconv"
+	"sort"
+	"time"
 
 	"e-commerce_marketplace/internal/models"
 	"e-commerce_marketplace/internal/repositories"
 	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 type WalletService interface {
-	CreateWallet(walletUserID string) (*models.Wallet, error)
+	CreateWallet(req *utils.CreateWalletRequest) (*models.Wallet, error)
 	GetWallet(walletUserID string) (*models.Wallet, error)
-	AddBalance(walletUserID string, req *utils.UpdateBalanceRequest) (*models.Wallet, error)
-	DeductBalance(walletUserID string, req *utils.UpdateBalanceRequest) (*models.Wallet, error)
+	ListUserWallets(ownerID string) ([]models.Wallet, error)
+	ChangeWalletStatus(walletUserID string, req *utils.ChangeWalletStatusRequest) (*models.WalletStatusChange, error)
+	ListWalletStatusChanges(walletUserID string) ([]models.WalletStatusChange, error)
+	AddBalance(walletUserID string, req *utils.UpdateBalanceRequest) (*models.BalanceUpdateResult, error)
+	DeductBalance(walletUserID string, req *utils.UpdateBalanceRequest) (*models.BalanceUpdateResult, error)
+	ListTransactions(walletUserID string, req *utils.ListTransactionsRequest) (*models.TransactionPage, error)
+	Transfer(req *utils.TransferRequest) (*models.TransferResult, error)
+	CreateHold(walletUserID string, req *utils.CreateHoldRequest) (*models.Hold, error)
+	GetHold(walletUserID, holdID string) (*models.Hold, error)
+	CaptureHold(walletUserID, holdID string, req *utils.CaptureHoldRequest) (*models.HoldCaptureResult, error)
+	ReleaseHold(walletUserID, holdID string) (*models.Hold, error)
+	ExpireHolds(now time.Time) (int, error)
+	ReverseTransaction(transactionID string, req *utils.ReverseTransactionRequest) (*models.BalanceUpdateResult, error)
 }
 
+// Balance operations, used to fingerprint idempotent requests
+const (
+	operationCredit   = "credit"
+	operationDebit    = "debit"
+	operationTransfer = "transfer"
+	operationReversal = "reversal"
+)
+
+// initialBalanceReference marks the ledger entries of a new wallet's initial balances
+const initialBalanceReference = "initial_balance"
+
 type walletService struct {
-	walletRepo repositories.WalletRepository
+	walletRepo      repositories.WalletRepository
+	transactionRepo repositories.TransactionRepository
+	holdRepo        repositories.HoldRepository
+	txManager       repositories.TxManager
+	balanceTypes    BalanceTypeProvider
+	events          EventPublisher
+	holdTTL         time.Duration
 }
 
-func NewWalletService(walletRepo repositories.WalletRepository) WalletService {
+func NewWalletService(walletRepo repositories.WalletRepository, transactionRepo repositories.TransactionRepository, holdRepo repositories.HoldRepository, txManager repositories.TxManager, balanceTypes BalanceTypeProvider, events EventPublisher, holdTTL time.Duration) WalletService {
 	return &walletService{
-		walletRepo: walletRepo,
+		walletRepo:      walletRepo,
+		transactionRepo: transactionRepo,
+		holdRepo:        holdRepo,
+		txManager:       txManager,
+		balanceTypes:    balanceTypes,
+		events:          events,
+		holdTTL:         holdTTL,
 	}
 }
 
-func (s *walletService) CreateWallet(walletUserID string) (*models.Wallet, error) {
-	exists, err := s.walletRepo.ExistsByWalletUserID(walletUserID)
+func (s *walletService) CreateWallet(req *utils.CreateWalletRequest) (*models.Wallet, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, utils.NewWalletError(utils.CodeValidationError, "Validation failed", fmt.Sprintf("%s: %s", validationErrors[0].Field, validationErrors[0].Message))
+	}
+	if err := utils.ValidateWalletUserID(req.WalletUserID); err != nil {
+		return nil, err
+	}
+
+	// wallet tanpa owner: pemiliknya wallet user itu sendiri
+	ownerID := req.OwnerID
+	if ownerID == "" {
+		ownerID = req.WalletUserID
+	}
+	kind := req.Kind
+	if kind == "" {
+		kind = models.WalletKindCustomer
+	}
+
+	exists, err := s.walletRepo.ExistsByWalletUserID(req.WalletUserID)
 	if err != nil {
 		return nil, err
 	}
@@ -35,139 +90,257 @@ func (s *walletService) CreateWallet(walletUserID string) (*models.Wallet, error
 		return nil, utils.NewWalletError(utils.CodeWalletExists, "Wallet already exists for this user", "")
 	}
 
-	// get all type from frappe
-	types, err := utils.GetAllBalanceTypesFromFrappe()
+	// get all types from the balance type registry
+	types, err := s.balanceTypes.BalanceTypes()
 	if err != nil {
 		return nil, err
 	}
 
-	var initialBalances models.BalanceData
-	if initialBalances == nil {
-		initialBalances = make(models.BalanceData)
-	}
+	initialBalances := make(models.BalanceData)
 	for _, t := range types {
-		initialBalances[t] = 0
+		initialBalances[t.Name] = decimal.Zero
+	}
+
+	// saldo awal harus valid sebelum wallet dibuat
+	credits, err := s.parseInitialBalances(req.InitialBalances)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := marshalMetadata(req.Metadata)
+	if err != nil {
+		return nil, err
 	}
 
 	// create new wallet
-	wallet := &models.Wallet{WalletUserID: walletUserID}
-	if err := wallet.SetBalances(&initialBalances); err != nil {
-		return nil, utils.NewWalletError(utils.CodeInternalError, "Failed to set initial balances", err.Error())
+	wallet := &models.Wallet{
+		WalletUserID: req.WalletUserID,
+		OwnerID:      ownerID,
+		Kind:         kind,
+		Balances:     initialBalances,
+		Metadata:     metadata,
 	}
 
-	if err := s.walletRepo.Create(wallet); err != nil {
+	err = s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.walletRepo.WithTx(tx).Create(wallet); err != nil {
+			return err
+		}
+		if err := s.publish(tx, models.EventWalletCreated, wallet.WalletUserID, wallet); err != nil {
+			return err
+		}
+		if len(credits) == 0 {
+			return nil
+		}
+
+		// saldo awal dicatat di ledger seperti credit biasa
+		locked, err := s.walletRepo.WithTx(tx).GetByWalletUserIDForUpdate(wallet.ID)
+		if err != nil {
+			return err
+		}
+		for _, credit := range credits {
+			if err := s.applyEntry(tx, locked, credit); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
-	return wallet, nil
+	return s.walletRepo.GetByWalletUserID(wallet.WalletUserID)
+}
+
+// parseInitialBalances validates the initial balances of a new wallet and
+// returns one credit entry per non-zero balance, ordered by balance type
+func (s *walletService) parseInitialBalances(balances map[string]string) ([]*models.Transaction, error) {
+	names := make([]string, 0, len(balances))
+	for name := range balances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	credits := make([]*models.Transaction, 0, len(names))
+	for _, name := range names {
+		if value, err := decimal.NewFromString(balances[name]); err == nil && value.IsZero() {
+			continue
+		}
+		amount, err := s.parseScaledAmount(name, balances[name])
+		if err != nil {
+			return nil, err
+		}
+		entry, err := newLedgerEntry(name, amount, initialBalanceReference, nil)
+		if err != nil {
+			return nil, err
+		}
+		credits = append(credits, entry)
+	}
+	return credits, nil
 }
 
 func (s *walletService) GetWallet(walletUserID string) (*models.Wallet, error) {
 	return s.walletRepo.GetByWalletUserID(walletUserID)
 }
 
-func (s *walletService) AddBalance(walletUserID string, req *utils.UpdateBalanceRequest) (*models.Wallet, error) {
-	// validasi request struct
-	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
-		return nil, utils.NewWalletError(utils.CodeValidationError, "Validation failed", "")
-	}
+func (s *walletService) ListUserWallets(ownerID string) ([]models.Wallet, error) {
+	return s.walletRepo.ListByOwnerID(ownerID)
+}
 
-	// parse amount
-	amountFloat, err := strconv.ParseFloat(req.Amount, 64)
-	if err != nil {
-		return nil, utils.NewWalletError(utils.CodeInvalidAmount, "amount must be a number", err.Error())
-	}
+func (s *walletService) AddBalance(walletUserID string, req *utils.UpdateBalanceRequest) (*models.BalanceUpdateResult, error) {
+	result, err := s.updateBalance(walletUserID, operationCredit, req)
+	recordBalanceOperation(operationCredit, req.BalanceType, result != nil && result.Replayed, err)
+	return result, err
+}
 
-	// validasi balance type via Frappe
-	if err := utils.ValidateBalanceTypeFromFrappe(req.BalanceType); err != nil {
-		return nil, err
-	}
-	if err := utils.ValidateAmount(amountFloat); err != nil {
-		return nil, err
-	}
+func (s *walletService) DeductBalance(walletUserID string, req *utils.UpdateBalanceRequest) (*models.BalanceUpdateResult, error) {
+	result, err := s.updateBalance(walletUserID, operationDebit, req)
+	recordBalanceOperation(operationDebit, req.BalanceType, result != nil && result.Replayed, err)
+	return result, err
+}
 
-	// ambil wallet
-	wallet, err := s.walletRepo.GetByWalletUserID(walletUserID)
+// updateBalance credits or debits a single wallet and records the ledger entry
+func (s *walletService) updateBalance(walletUserID, operation string, req *utils.UpdateBalanceRequest) (*models.BalanceUpdateResult, error) {
+	amount, err := s.validateBalanceRequest(req)
 	if err != nil {
 		return nil, err
 	}
 
-	balances, err := wallet.GetBalances()
-	if err != nil {
-		return nil, utils.NewWalletError(utils.CodeInternalError, "Failed to parse balances", err.Error())
-	}
-	if *balances == nil {
-		*balances = make(models.BalanceData)
-	}
+	result := &models.BalanceUpdateResult{}
+	err = s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		// ambil wallet + lock row supaya request paralel tidak lolos cek saldo bersamaan
+		wallet, err := s.walletRepo.WithTx(tx).GetByWalletUserIDForUpdate(walletUserID)
+		if err != nil {
+			return err
+		}
 
-	if _, ok := (*balances)[req.BalanceType]; !ok {
-		(*balances)[req.BalanceType] = 0
-	}
+		hash := requestHash(operation, req.BalanceType, amount, req.Reference, req.Metadata)
+
+		// request yang sama diulang: kembalikan transaksi aslinya
+		original, err := s.findIdempotentReplay(tx, wallet.WalletUserID, req.IdempotencyKey, hash)
+		if err != nil {
+			return err
+		}
+		if original != nil {
+			result.Transaction = original
+			result.Replayed = true
+			return nil
+		}
 
-	// add balance
-	(*balances)[req.BalanceType] += amountFloat
+		signedAmount := amount
+		if operation == operationDebit {
+			signedAmount = amount.Neg()
+		}
+		entry, err := newLedgerEntry(req.BalanceType, signedAmount, req.Reference, req.Metadata)
+		if err != nil {
+			return err
+		}
+		if req.IdempotencyKey != "" {
+			entry.IdempotencyKey = &req.IdempotencyKey
+			entry.RequestHash = hash
+		}
 
-	// update DB
-	if err := s.walletRepo.UpdateBalances(walletUserID, balances); err != nil {
+		// update DB + ledger dalam transaksi yang sama
+		if err := s.applyEntry(tx, wallet, entry); err != nil {
+			return err
+		}
+		result.Transaction = entry
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return s.walletRepo.GetByWalletUserID(walletUserID)
+	if result.Wallet, err = s.walletRepo.GetByWalletUserID(walletUserID); err != nil {
+		return nil, err
+	}
+	if result.Replayed {
+		replayBalance(result.Wallet, result.Transaction)
+	}
+	return result, nil
 }
 
-func (s *walletService) DeductBalance(walletUserID string, req *utils.UpdateBalanceRequest) (*models.Wallet, error) {
+// validateBalanceRequest validates an add/deduct request and returns the parsed amount
+func (s *walletService) validateBalanceRequest(req *utils.UpdateBalanceRequest) (decimal.Decimal, error) {
 	// validasi request struct
 	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
-		return nil, utils.NewWalletError(utils.CodeValidationError, "Validation failed", "")
+		return decimal.Zero, utils.NewWalletError(utils.CodeValidationError, "Validation failed", "")
 	}
 
-	// parse amount
-	amountFloat, err := strconv.ParseFloat(req.Amount, 64)
+	amount, _, err := s.validateAmount(req.BalanceType, req.Amount)
+	return amount, err
+}
+
+// validateAmount looks up the balance type and parses a positive amount
+// within its decimal scale and per-transaction limits
+func (s *walletService) validateAmount(balanceType, amount string) (decimal.Decimal, *models.BalanceType, error) {
+	// validasi balance type (cache dari registry)
+	bt, err := s.balanceTypes.Get(balanceType)
 	if err != nil {
-		return nil, utils.NewWalletError(utils.CodeInvalidAmount, "amount must be a number", err.Error())
+		return decimal.Zero, nil, err
 	}
 
-	// validasi balance type via Frappe
-	if err := utils.ValidateBalanceTypeFromFrappe(req.BalanceType); err != nil {
-		return nil, err
+	value, err := utils.ParseAmount(amount, bt.Scale)
+	if err != nil {
+		return decimal.Zero, nil, err
 	}
-	if err := utils.ValidateAmount(amountFloat); err != nil {
-		return nil, err
+	if bt.MinPerTransaction.Valid && value.LessThan(bt.MinPerTransaction.Decimal) {
+		return decimal.Zero, nil, utils.NewWalletError(utils.CodeInvalidAmount, "amount is below the minimum per transaction", fmt.Sprintf("minimum %s %s", bt.MinPerTransaction.Decimal, bt.Name))
 	}
-
-	wallet, err := s.walletRepo.GetByWalletUserID(walletUserID)
-	if err != nil {
-		return nil, err
+	if bt.MaxPerTransaction.Valid && value.GreaterThan(bt.MaxPerTransaction.Decimal) {
+		return decimal.Zero, nil, utils.NewWalletError(utils.CodeInvalidAmount, "amount exceeds the maximum per transaction", fmt.Sprintf("maximum %s %s", bt.MaxPerTransaction.Decimal, bt.Name))
 	}
+	return value, bt, nil
+}
 
-	// get balance
-	balances, err := wallet.GetBalances()
+// parseScaledAmount parses an amount that only needs to respect the balance
+// type's scale, such as partial captures and refunds of earlier operations
+func (s *walletService) parseScaledAmount(balanceType, amount string) (decimal.Decimal, error) {
+	bt, err := s.balanceTypes.Get(balanceType)
 	if err != nil {
-		return nil, utils.NewWalletError(utils.CodeInternalError, "Failed to parse balances", err.Error())
-	}
-	if *balances == nil {
-		*balances = make(models.BalanceData)
+		return decimal.Zero, err
 	}
+	return utils.ParseAmount(amount, bt.Scale)
+}
 
-	if _, ok := (*balances)[req.BalanceType]; !ok {
-		(*balances)[req.BalanceType] = 0
+// applyEntry applies a signed ledger entry to a wallet that is locked in tx:
+// it rejects movements the wallet's status does not allow, adjusts the
+// balance, rejects debits above the available balance and appends the entry
+// to the ledger
+func (s *walletService) applyEntry(tx *gorm.DB, wallet *models.Wallet, entry *models.Transaction) error {
+	if err := checkWalletStatus(wallet, entry.Amount.IsNegative()); err != nil {
+		return err
 	}
+	return s.postEntry(tx, wallet, entry)
+}
 
-	// cek sufficient balance
-	if (*balances)[req.BalanceType] < amountFloat {
-		return nil, utils.NewWalletError(
+// postEntry is applyEntry without the wallet status check. It is only used
+// to sweep the balances out of a wallet that is being closed.
+func (s *walletService) postEntry(tx *gorm.DB, wallet *models.Wallet, entry *models.Transaction) error {
+	// cek sufficient balance (saldo yang di-hold tidak bisa dipakai)
+	available := wallet.Available[entry.BalanceType]
+	if entry.Amount.IsNegative() && available.Add(entry.Amount).IsNegative() {
+		return utils.NewWalletError(
 			utils.CodeInsufficientBalance,
-			fmt.Sprintf("Insufficient %s balance", req.BalanceType),
-			fmt.Sprintf("Current balance: %.2f, required: %.2f", (*balances)[req.BalanceType], amountFloat),
+			fmt.Sprintf("Insufficient %s balance", entry.BalanceType),
+			fmt.Sprintf("Available balance: %s, required: %s", available, entry.Amount.Neg()),
 		)
 	}
 
-	// deduct balance
-	(*balances)[req.BalanceType] -= amountFloat
+	row, err := s.walletRepo.WithTx(tx).AdjustBalance(wallet.WalletUserID, entry.BalanceType, entry.Amount)
+	if err != nil {
+		return err
+	}
+	// keep the locked wallet in sync for later entries in the same transaction
+	wallet.SetBalanceRow(*row)
 
-	// update DB
-	if err := s.walletRepo.UpdateBalances(walletUserID, balances); err != nil {
-		return nil, err
+	entry.WalletUserID = wallet.WalletUserID
+	entry.BalanceAfter = row.Amount
+	if err := s.transactionRepo.WithTx(tx).Create(entry); err != nil {
+		return err
 	}
 
-	return s.walletRepo.GetByWalletUserID(walletUserID)
+	eventType := models.EventBalanceCredited
+	if entry.Amount.IsNegative() {
+		eventType = models.EventBalanceDebited
+	}
+	return s.publish(tx, eventType, wallet.WalletUserID, entry)
 }
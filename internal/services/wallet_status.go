@@ -0,0 +1,143 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// walletCloseSweepReference marks the ledger entries that move the remaining
+// balances out of a wallet that is being closed
+const walletCloseSweepReference = "wallet_close_sweep"
+
+func (s *walletService) ChangeWalletStatus(walletUserID string, req *utils.ChangeWalletStatusRequest) (*models.WalletStatusChange, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, utils.NewWalletError(utils.CodeValidationError, "Validation failed", fmt.Sprintf("%s: %s", validationErrors[0].Field, validationErrors[0].Message))
+	}
+	if req.SweepTo != "" && req.Status != models.WalletStatusClosed {
+		return nil, utils.NewWalletError(utils.CodeValidationError, "sweep_to is only allowed when closing a wallet", "")
+	}
+
+	var change *models.WalletStatusChange
+	err := s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		var wallet, destination *models.Wallet
+		var err error
+		if req.SweepTo != "" {
+			wallet, destination, err = lockWalletPair(s.walletRepo.WithTx(tx), walletUserID, req.SweepTo)
+		} else {
+			wallet, err = s.walletRepo.WithTx(tx).GetByWalletUserIDForUpdate(walletUserID)
+		}
+		if err != nil {
+			return err
+		}
+
+		if wallet.Status == models.WalletStatusClosed {
+			return utils.NewWalletError(utils.CodeInvalidStatusTransition, "Closed wallets cannot be changed", "")
+		}
+		if wallet.Status == req.Status {
+			return utils.NewWalletError(utils.CodeInvalidStatusTransition, fmt.Sprintf("Wallet is already %s", req.Status), "")
+		}
+
+		change = &models.WalletStatusChange{
+			WalletUserID: wallet.WalletUserID,
+			FromStatus:   wallet.Status,
+			ToStatus:     req.Status,
+			Reason:       req.Reason,
+		}
+
+		if req.Status == models.WalletStatusClosed {
+			transferID, err := s.sweepWallet(tx, wallet, destination, req.Reason)
+			if err != nil {
+				return err
+			}
+			change.SweepTransferID = transferID
+		}
+
+		if err := s.walletRepo.WithTx(tx).UpdateStatus(wallet.WalletUserID, req.Status); err != nil {
+			return err
+		}
+		return s.walletRepo.WithTx(tx).CreateStatusChange(change)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+func (s *walletService) ListWalletStatusChanges(walletUserID string) ([]models.WalletStatusChange, error) {
+	wallet, err := s.walletRepo.GetByWalletUserID(walletUserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.walletRepo.ListStatusChanges(wallet.WalletUserID)
+}
+
+// sweepWallet empties a wallet that is being closed by moving every non-zero
+// balance to destination as one transfer. Without a destination the wallet
+// must already be empty. Wallets with active holds cannot be closed.
+func (s *walletService) sweepWallet(tx *gorm.DB, wallet, destination *models.Wallet, reason string) (*string, error) {
+	for balanceType, held := range wallet.Held {
+		if held.IsPositive() {
+			return nil, utils.NewWalletError(utils.CodeWalletNotEmpty, "Wallet has active holds", fmt.Sprintf("%s held: %s", balanceType, held))
+		}
+	}
+
+	var balanceTypes []string
+	for balanceType, amount := range wallet.Balances {
+		if amount.IsPositive() {
+			balanceTypes = append(balanceTypes, balanceType)
+		}
+	}
+	if len(balanceTypes) == 0 {
+		return nil, nil
+	}
+	if destination == nil {
+		return nil, utils.NewWalletError(utils.CodeWalletNotEmpty, "Wallet still has balance; empty it or pass sweep_to", "")
+	}
+	sort.Strings(balanceTypes)
+
+	// saldo sisa dipindah ke wallet tujuan, dicatat sebagai transfer
+	transferID := uuid.New().String()
+	metadata := map[string]interface{}{"reason": reason}
+	for _, balanceType := range balanceTypes {
+		amount := wallet.Balances[balanceType]
+
+		debit, err := newLedgerEntry(balanceType, amount.Neg(), walletCloseSweepReference, metadata)
+		if err != nil {
+			return nil, err
+		}
+		debit.TransferID = &transferID
+
+		credit, err := newLedgerEntry(balanceType, amount, walletCloseSweepReference, metadata)
+		if err != nil {
+			return nil, err
+		}
+		credit.TransferID = &transferID
+
+		// wallet yang ditutup boleh frozen/debit-blocked, jadi status tidak dicek di sini
+		if err := s.postEntry(tx, wallet, debit); err != nil {
+			return nil, err
+		}
+		if err := s.applyEntry(tx, destination, credit); err != nil {
+			return nil, err
+		}
+	}
+	return &transferID, nil
+}
+
+// checkWalletStatus rejects balance movements that the wallet's status does
+// not allow
+func checkWalletStatus(wallet *models.Wallet, debit bool) error {
+	if debit && !wallet.CanDebit() {
+		return utils.NewWalletError(utils.CodeWalletNotActive, "Wallet does not allow debits", fmt.Sprintf("status: %s", wallet.Status))
+	}
+	if !debit && !wallet.CanCredit() {
+		return utils.NewWalletError(utils.CodeWalletNotActive, "Wallet does not allow credits", fmt.Sprintf("status: %s", wallet.Status))
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"sync"
+	"testing"
+
+	"e-commerce_marketplace/pkg/utils"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestConcurrentDeductsOnOneWallet(t *testing.T) {
+	env := newTestEnv(t)
+	wallet := env.createWallet(t, map[string]string{"Coins": "100"})
+
+	// 50 x 3 Coins melebihi saldo 100: tepat 33 yang boleh berhasil
+	const workers = 50
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+		failures  []error
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := env.wallets.DeductBalance(wallet.WalletUserID, &utils.UpdateBalanceRequest{BalanceType: "Coins", Amount: "3"})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				succeeded++
+			} else {
+				failures = append(failures, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range failures {
+		assertErrorCode(t, err, utils.CodeInsufficientBalance)
+	}
+	if succeeded != 33 {
+		t.Errorf("%d deducts succeeded, want 33", succeeded)
+	}
+	if got := env.balance(t, wallet.WalletUserID, "Coins"); !got.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("final balance = %s, want 1", got)
+	}
+	if debits := env.countTransactions(t, wallet.WalletUserID, "amount < 0"); debits != int64(succeeded) {
+		t.Errorf("%d debit entries in the ledger, want %d", debits, succeeded)
+	}
+}
+
+func TestWalletKeyResolvesWalletIDFirst(t *testing.T) {
+	env := newTestEnv(t)
+	wallet := env.createWallet(t, map[string]string{"Coins": "10"})
+
+	// wallet user ID yang sama dengan ID wallet lain ditolak
+	_, err := env.wallets.CreateWallet(&utils.CreateWalletRequest{WalletUserID: wallet.ID})
+	assertErrorCode(t, err, utils.CodeWalletExists)
+
+	// data lama bisa saja sudah ambigu: ID wallet menang
+	shadow := env.createWallet(t, map[string]string{"Coins": "10"})
+	if err := env.db.Exec("UPDATE wallets SET wallet_user_id = ? WHERE id = ?", wallet.ID, shadow.ID).Error; err != nil {
+		t.Fatalf("make wallet key ambiguous: %v", err)
+	}
+	if err := env.db.Exec("UPDATE wallet_balances SET wallet_user_id = ? WHERE wallet_user_id = ?", wallet.ID, shadow.WalletUserID).Error; err != nil {
+		t.Fatalf("move shadow balances: %v", err)
+	}
+
+	found, err := env.wallets.GetWallet(wallet.ID)
+	if err != nil {
+		t.Fatalf("get wallet: %v", err)
+	}
+	if found.ID != wallet.ID {
+		t.Errorf("key %s resolved to wallet %s", wallet.ID, found.ID)
+	}
+
+	destination := env.createWallet(t, nil)
+	if _, err := env.wallets.Transfer(&utils.TransferRequest{
+		FromWalletUserID: wallet.ID,
+		ToWalletUserID:   destination.ID,
+		BalanceType:      "Coins",
+		Amount:           "4",
+	}); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if got := env.balance(t, wallet.WalletUserID, "Coins"); !got.Equal(decimal.NewFromInt(6)) {
+		t.Errorf("source balance = %s, want 6", got)
+	}
+	if got := env.balance(t, destination.WalletUserID, "Coins"); !got.Equal(decimal.NewFromInt(4)) {
+		t.Errorf("destination balance = %s, want 4", got)
+	}
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/internal/repositories"
+	"e-commerce_marketplace/pkg/utils"
+
+	"gorm.io/datatypes"
+)
+
+const defaultWebhookDeliveryPageSize = 50
+
+// WebhookCredentials is returned when a subscription is created. The secret
+// is only shown this once.
+type WebhookCredentials struct {
+	*models.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+type WebhookService interface {
+	EventSink
+
+	CreateSubscription(req *utils.CreateWebhookRequest) (*WebhookCredentials, error)
+	GetSubscription(id string) (*models.WebhookSubscription, error)
+	ListSubscriptions() ([]models.WebhookSubscription, error)
+	UpdateSubscription(id string, req *utils.UpdateWebhookRequest) (*models.WebhookSubscription, error)
+	DeleteSubscription(id string) error
+	ListDeliveries(subscriptionID string, req *utils.ListWebhookDeliveriesRequest) ([]models.WebhookDelivery, error)
+
+	// Redeliver queues a delivery again, e.g. after it went dead
+	Redeliver(deliveryID string) (*models.WebhookDelivery, error)
+}
+
+type webhookService struct {
+	webhookRepo repositories.WebhookRepository
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(webhookRepo repositories.WebhookRepository) WebhookService {
+	return &webhookService{webhookRepo: webhookRepo}
+}
+
+func (s *webhookService) Name() string {
+	return EventSinkWebhook
+}
+
+// Send queues the event for every active subscription that wants it. An
+// event sent again by the outbox does not queue a second delivery.
+func (s *webhookService) Send(ctx context.Context, event *models.Event) error {
+	subscriptions, err := s.webhookRepo.ListActiveSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	var payload []byte
+	var deliveries []models.WebhookDelivery
+	for _, subscription := range subscriptions {
+		if !subscription.Subscribes(event.Type) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				return utils.NewWalletError(utils.CodeInternalError, "Failed to encode event", err.Error())
+			}
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        datatypes.JSON(payload),
+			Status:         models.WebhookDeliveryPending,
+			NextAttemptAt:  event.CreatedAt,
+		})
+	}
+	return s.webhookRepo.CreateDeliveries(deliveries)
+}
+
+func (s *webhookService) CreateSubscription(req *utils.CreateWebhookRequest) (*WebhookCredentials, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, utils.NewWalletError(utils.CodeValidationError, "Validation failed", fmt.Sprintf("%s: %s", validationErrors[0].Field, validationErrors[0].Message))
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		generated, err := randomToken(32)
+		if err != nil {
+			return nil, err
+		}
+		secret = "whsec_" + generated
+	}
+
+	subscription := &models.WebhookSubscription{
+		URL:        req.URL,
+		EventTypes: strings.Join(req.EventTypes, " "),
+		Secret:     secret,
+		Active:     true,
+	}
+	if err := s.webhookRepo.CreateSubscription(subscription); err != nil {
+		return nil, err
+	}
+	return &WebhookCredentials{WebhookSubscription: subscription, Secret: secret}, nil
+}
+
+func (s *webhookService) GetSubscription(id string) (*models.WebhookSubscription, error) {
+	return s.webhookRepo.GetSubscription(id)
+}
+
+func (s *webhookService) ListSubscriptions() ([]models.WebhookSubscription, error) {
+	return s.webhookRepo.ListSubscriptions()
+}
+
+func (s *webhookService) UpdateSubscription(id string, req *utils.UpdateWebhookRequest) (*models.WebhookSubscription, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, utils.NewWalletError(utils.CodeValidationError, "Validation failed", fmt.Sprintf("%s: %s", validationErrors[0].Field, validationErrors[0].Message))
+	}
+
+	subscription, err := s.webhookRepo.GetSubscription(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.URL != nil {
+		subscription.URL = *req.URL
+	}
+	if len(req.EventTypes) > 0 {
+		subscription.EventTypes = strings.Join(req.EventTypes, " ")
+	}
+	if req.Active != nil {
+		subscription.Active = *req.Active
+	}
+
+	if err := s.webhookRepo.UpdateSubscription(subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *webhookService) DeleteSubscription(id string) error {
+	return s.webhookRepo.DeleteSubscription(id)
+}
+
+func (s *webhookService) ListDeliveries(subscriptionID string, req *utils.ListWebhookDeliveriesRequest) ([]models.WebhookDelivery, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, utils.NewWalletError(utils.CodeValidationError, "Validation failed", fmt.Sprintf("%s: %s", validationErrors[0].Field, validationErrors[0].Message))
+	}
+	if _, err := s.webhookRepo.GetSubscription(subscriptionID); err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultWebhookDeliveryPageSize
+	}
+	return s.webhookRepo.ListDeliveries(subscriptionID, req.Status, limit)
+}
+
+func (s *webhookService) Redeliver(deliveryID string) (*models.WebhookDelivery, error) {
+	delivery, err := s.webhookRepo.GetDelivery(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	// mulai ulang siklus retry dari awal
+	delivery.Status = models.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	if err := s.webhookRepo.UpdateDelivery(delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/internal/repositories"
+	"e-commerce_marketplace/pkg/utils"
+)
+
+// WebhookDispatcherConfig configures webhook delivery
+type WebhookDispatcherConfig struct {
+	// Interval between polls for due deliveries
+	Interval time.Duration
+
+	// BatchSize is the maximum number of deliveries sent per poll
+	BatchSize int
+
+	// Timeout of a single HTTP request
+	Timeout time.Duration
+
+	// MaxAttempts before a delivery is marked dead
+	MaxAttempts int
+
+	// Retry backoff starts at BaseBackoff, doubles per attempt and is capped at MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// DefaultWebhookDispatcherConfig returns the default webhook delivery settings
+func DefaultWebhookDispatcherConfig() WebhookDispatcherConfig {
+	return WebhookDispatcherConfig{
+		Interval:    5 * time.Second,
+		BatchSize:   50,
+		Timeout:     10 * time.Second,
+		MaxAttempts: 8,
+		BaseBackoff: 30 * time.Second,
+		MaxBackoff:  time.Hour,
+	}
+}
+
+// WebhookDispatcher sends queued webhook deliveries and retries failed ones
+type WebhookDispatcher struct {
+	webhookRepo repositories.WebhookRepository
+	client      *http.Client
+	config      WebhookDispatcherConfig
+}
+
+// NewWebhookDispatcher creates a new webhook dispatcher
+func NewWebhookDispatcher(webhookRepo repositories.WebhookRepository, config WebhookDispatcherConfig) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		webhookRepo: webhookRepo,
+		client:      &http.Client{Timeout: config.Timeout},
+		config:      config,
+	}
+}
+
+// Run sends due deliveries every interval until ctx is cancelled
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.dispatch(ctx); err != nil {
+				log.Printf("[WebhookDispatcher] failed to dispatch deliveries: %v", err)
+			}
+		}
+	}
+}
+
+// dispatch claims the due deliveries and sends them one by one. Claimed
+// deliveries are leased for two request timeouts so that a crash in between
+// only delays them.
+func (d *WebhookDispatcher) dispatch(ctx context.Context) error {
+	deliveries, err := d.webhookRepo.ClaimDueDeliveries(time.Now(), 2*d.config.Timeout, d.config.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	subscriptions := make(map[string]*models.WebhookSubscription)
+	for i := range deliveries {
+		// sisa delivery dikirim ulang setelah lease habis
+		if ctx.Err() != nil {
+			return nil
+		}
+		delivery := &deliveries[i]
+
+		subscription, ok := subscriptions[delivery.SubscriptionID]
+		if !ok {
+			subscription, err = d.webhookRepo.GetSubscription(delivery.SubscriptionID)
+			if err != nil && utils.GetErrorCode(err) != utils.CodeWebhookNotFound {
+				return err
+			}
+			subscriptions[delivery.SubscriptionID] = subscription
+		}
+
+		// subscription dihapus/nonaktif: tidak ada yang menerima lagi
+		if subscription == nil || !subscription.Active {
+			delivery.Status = models.WebhookDeliveryDead
+			delivery.LastError = "subscription is deleted or inactive"
+		} else {
+			d.send(ctx, subscription, delivery)
+		}
+
+		if err := d.webhookRepo.UpdateDelivery(delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// send makes one delivery attempt and schedules the next one on failure
+func (d *WebhookDispatcher) send(ctx context.Context, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) {
+	delivery.Attempts++
+
+	statusCode, err := d.post(ctx, subscription, delivery)
+	delivery.LastStatusCode = statusCode
+	if err == nil {
+		now := time.Now()
+		delivery.Status = models.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= d.config.MaxAttempts {
+		delivery.Status = models.WebhookDeliveryDead
+		log.Printf("[WebhookDispatcher] delivery %s to %s is dead after %d attempts: %v", delivery.ID, subscription.URL, delivery.Attempts, err)
+		return
+	}
+	delivery.NextAttemptAt = time.Now().Add(d.backoff(delivery.Attempts))
+}
+
+// post sends the signed payload and treats any 2xx response as delivered
+func (d *WebhookDispatcher) post(ctx context.Context, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) (int, error) {
+	// delivery yang sedang berjalan diselesaikan walau ctx dibatalkan
+	req, err := http.NewRequestWithContext(context.WithoutCancel(ctx), http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Wallet-Event", delivery.EventType)
+	req.Header.Set("X-Wallet-Delivery", delivery.ID)
+	req.Header.Set("X-Wallet-Signature", utils.SignPayload(subscription.Secret, time.Now().Unix(), delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the next attempt: exponential with up to
+// 20% jitter so that many failed deliveries don't retry in lockstep
+func (d *WebhookDispatcher) backoff(attempts int) time.Duration {
+	delay := d.config.BaseBackoff << (attempts - 1)
+	if delay <= 0 || delay > d.config.MaxBackoff {
+		delay = d.config.MaxBackoff
+	}
+	return delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"e-commerce_marketplace/internal/models"
+	"e-commerce_marketplace/pkg/utils"
+
+	"gorm.io/datatypes"
+)
+
+func newTestDispatcher() *WebhookDispatcher {
+	config := DefaultWebhookDispatcherConfig()
+	config.Timeout = 5 * time.Second
+	config.MaxAttempts = 2
+	return NewWebhookDispatcher(nil, config)
+}
+
+func TestWebhookDeliveryIsSigned(t *testing.T) {
+	const secret = "whsec_test"
+	payload := []byte(`{"id":"evt-1","type":"wallet.credited"}`)
+
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	subscription := &models.WebhookSubscription{URL: server.URL, Secret: secret, Active: true}
+	delivery := &models.WebhookDelivery{ID: "delivery-1", EventType: "wallet.credited", Payload: datatypes.JSON(payload)}
+	newTestDispatcher().send(context.Background(), subscription, delivery)
+
+	if delivery.Status != models.WebhookDeliveryDelivered || delivery.DeliveredAt == nil || delivery.LastStatusCode != http.StatusNoContent {
+		t.Fatalf("got status %s (code %d, error %q)", delivery.Status, delivery.LastStatusCode, delivery.LastError)
+	}
+
+	req, body := <-received, <-bodies
+	if string(body) != string(payload) {
+		t.Errorf("got body %s, want %s", body, payload)
+	}
+	if req.Header.Get("X-Wallet-Event") != "wallet.credited" || req.Header.Get("X-Wallet-Delivery") != "delivery-1" {
+		t.Errorf("unexpected headers: %v", req.Header)
+	}
+
+	// penerima memverifikasi dengan timestamp dari header yang sama
+	signature := req.Header.Get("X-Wallet-Signature")
+	timestamp, err := strconv.ParseInt(strings.TrimPrefix(strings.SplitN(signature, ",", 2)[0], "t="), 10, 64)
+	if err != nil {
+		t.Fatalf("parse signature %q: %v", signature, err)
+	}
+	if time.Since(time.Unix(timestamp, 0)) > time.Minute {
+		t.Errorf("signature timestamp %d is not current", timestamp)
+	}
+	if want := utils.SignPayload(secret, timestamp, payload); signature != want {
+		t.Errorf("got signature %s, want %s", signature, want)
+	}
+}
+
+func TestFailedWebhookDeliveryIsRetriedThenDead(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	dispatcher := newTestDispatcher()
+	subscription := &models.WebhookSubscription{URL: server.URL, Secret: "whsec_test", Active: true}
+	delivery := &models.WebhookDelivery{ID: "delivery-1", EventType: "wallet.credited", Payload: datatypes.JSON(`{}`), Status: models.WebhookDeliveryPending}
+
+	before := time.Now()
+	dispatcher.send(context.Background(), subscription, delivery)
+	if delivery.Status != models.WebhookDeliveryPending || delivery.Attempts != 1 || delivery.LastStatusCode != http.StatusInternalServerError {
+		t.Fatalf("after first attempt got status %s, %d attempts, code %d", delivery.Status, delivery.Attempts, delivery.LastStatusCode)
+	}
+	if delivery.NextAttemptAt.Before(before.Add(dispatcher.config.BaseBackoff)) {
+		t.Errorf("next attempt at %s is before the backoff", delivery.NextAttemptAt)
+	}
+
+	dispatcher.send(context.Background(), subscription, delivery)
+	if delivery.Status != models.WebhookDeliveryDead || delivery.Attempts != 2 {
+		t.Errorf("after last attempt got status %s with %d attempts", delivery.Status, delivery.Attempts)
+	}
+}
+
+func TestWebhookBackoffIsCapped(t *testing.T) {
+	dispatcher := newTestDispatcher()
+	for attempts := 1; attempts <= 64; attempts++ {
+		delay := dispatcher.backoff(attempts)
+		if delay < dispatcher.config.BaseBackoff || delay > dispatcher.config.MaxBackoff*6/5 {
+			t.Errorf("backoff(%d) = %s", attempts, delay)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Package testdb gives tests a Postgres database of their own. Tests using it
+// are skipped unless TEST_DATABASE_DSN points at a database they may write to.
+package testdb
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"e-commerce_marketplace/internal/migrations"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// DSNEnv names the environment variable holding the test database DSN
+const DSNEnv = "TEST_DATABASE_DSN"
+
+// Open returns a connection to a new schema with all migrations applied
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	db := OpenEmpty(t)
+	migrator, err := migrations.New(db)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+	return db
+}
+
+// OpenEmpty returns a connection to a new, empty schema. The schema is
+// dropped when the test ends.
+func OpenEmpty(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv(DSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", DSNEnv)
+	}
+
+	admin, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("connect to test database: %v", err)
+	}
+	schema := "test_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+
+	db, err := gorm.Open(postgres.Open(withSearchPath(dsn, schema)), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("connect to test schema: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		if err := admin.Exec("DROP SCHEMA " + schema + " CASCADE").Error; err != nil {
+			t.Errorf("drop schema %s: %v", schema, err)
+		}
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// withSearchPath adds the search_path runtime parameter to a key/value or
+// URL style DSN
+func withSearchPath(dsn, schema string) string {
+	if strings.Contains(dsn, "://") {
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		return fmt.Sprintf("%s%ssearch_path=%s", dsn, separator, schema)
+	}
+	return fmt.Sprintf("%s search_path=%s", dsn, schema)
+}
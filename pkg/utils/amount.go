@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultBalanceScale is the number of decimal places used for balance types
+// without an explicit scale
+const DefaultBalanceScale int32 = 2
+
+// ParseAmount parses a decimal amount string and validates it for the given scale
+func ParseAmount(amount string, scale int32) (decimal.Decimal, error) {
+	value, err := decimal.NewFromString(strings.TrimSpace(amount))
+	if err != nil {
+		return decimal.Zero, NewWalletError(CodeInvalidAmount, "amount must be a number", err.Error())
+	}
+	if err := ValidateAmount(value, scale); err != nil {
+		return decimal.Zero, err
+	}
+	return value, nil
+}
+
+// FormatScale describes a scale for error messages
+func FormatScale(scale int32) string {
+	if scale == 0 {
+		return "whole numbers only"
+	}
+	return fmt.Sprintf("at most %d decimal places", scale)
+}
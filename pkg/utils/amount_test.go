@@ -0,0 +1,35 @@
+package utils
+
+import "testing"
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		amount string
+		scale  int32
+		want   string
+		code   string
+	}{
+		{amount: "10", scale: 0, want: "10"},
+		{amount: " 2.50 ", scale: 2, want: "2.5"},
+		{amount: "0.001", scale: 2, code: CodeInvalidAmount},
+		{amount: "1.5", scale: 0, code: CodeInvalidAmount},
+		{amount: "0", scale: 2, code: CodeInvalidAmount},
+		{amount: "-1", scale: 2, code: CodeInvalidAmount},
+		{amount: "1e3", scale: 0, want: "1000"},
+		{amount: "abc", scale: 2, code: CodeInvalidAmount},
+		{amount: "1000000001", scale: 0, code: CodeInvalidAmount},
+	}
+
+	for _, test := range tests {
+		value, err := ParseAmount(test.amount, test.scale)
+		if test.code != "" {
+			if walletErr, ok := err.(*WalletError); !ok || walletErr.Code != test.code {
+				t.Errorf("ParseAmount(%q, %d): got error %v, want %s", test.amount, test.scale, err, test.code)
+			}
+			continue
+		}
+		if err != nil || value.String() != test.want {
+			t.Errorf("ParseAmount(%q, %d) = %s, %v; want %s", test.amount, test.scale, value, err, test.want)
+		}
+	}
+}
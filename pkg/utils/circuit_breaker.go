@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreaker stops calls to a failing dependency. After threshold
+// consecutive failures it opens for cooldown; then a single trial call is let
+// through, which closes the breaker on success or re-opens it on failure.
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+// NewCircuitBreaker creates a new circuit breaker
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// Allow returns ErrCircuitOpen if the call should not be attempted
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return nil
+	}
+	if time.Now().Before(b.openUntil) || b.trial {
+		return ErrCircuitOpen
+	}
+	// half-open: biarkan satu percobaan lewat
+	b.trial = true
+	return nil
+}
+
+// Success records a successful call and closes the breaker
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.trial = false
+}
+
+// Failure records a failed call and opens the breaker once the threshold is reached
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trial = false
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// Open reports whether calls are currently being rejected
+func (b *CircuitBreaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.failures >= b.threshold && time.Now().Before(b.openUntil)
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	breaker := NewCircuitBreaker(2, 20*time.Millisecond)
+
+	breaker.Failure()
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("breaker opened below the threshold: %v", err)
+	}
+	breaker.Failure()
+	if err := breaker.Allow(); err != ErrCircuitOpen || !breaker.Open() {
+		t.Fatalf("breaker did not open at the threshold: %v", err)
+	}
+
+	// setelah cooldown hanya satu percobaan yang lewat
+	time.Sleep(25 * time.Millisecond)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("trial call was rejected: %v", err)
+	}
+	if err := breaker.Allow(); err != ErrCircuitOpen {
+		t.Fatalf("second call during the trial was allowed: %v", err)
+	}
+
+	breaker.Failure()
+	if err := breaker.Allow(); err != ErrCircuitOpen {
+		t.Fatalf("failed trial did not re-open the breaker: %v", err)
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("trial call was rejected: %v", err)
+	}
+	breaker.Success()
+	if err := breaker.Allow(); err != nil || breaker.Open() {
+		t.Fatalf("successful trial did not close the breaker: %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// batchCSVColumns are the columns a batch CSV upload must have, in any order
+var batchCSVColumns = []string{"wallet_user_id", "type", "amount"}
+
+// ParseBatchCSV reads batch rows from a CSV file with a header line
+// containing wallet_user_id, type and amount. Other columns are ignored.
+func ParseBatchCSV(r io.Reader) ([]BatchItemRequest, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, NewWalletError(CodeValidationError, "Invalid CSV file", "missing header line")
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")))] = i
+	}
+	for _, column := range batchCSVColumns {
+		if _, ok := index[column]; !ok {
+			return nil, NewWalletError(CodeValidationError, "Invalid CSV file", fmt.Sprintf("missing column %q", column))
+		}
+	}
+
+	var items []BatchItemRequest
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, NewWalletError(CodeValidationError, "Invalid CSV file", err.Error())
+		}
+
+		field := func(column string) string {
+			if i := index[column]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		items = append(items, BatchItemRequest{
+			WalletUserID: field("wallet_user_id"),
+			BalanceType:  field("type"),
+			Amount:       field("amount"),
+		})
+	}
+	return items, nil
+}
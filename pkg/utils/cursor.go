@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor builds an opaque pagination cursor from a timestamp and ID
+func EncodeCursor(createdAt time.Time, id string) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor. The ID must be a
+// UUID, so a tampered cursor is rejected before it reaches the database.
+func DecodeCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(parts[1]); err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	return createdAt, parts[1], nil
+}
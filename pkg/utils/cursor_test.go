@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 10, 30, 0, 123456789, time.FixedZone("WIB", 7*60*60))
+	id := uuid.New().String()
+
+	gotCreatedAt, gotID, err := DecodeCursor(EncodeCursor(createdAt, id))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !gotCreatedAt.Equal(createdAt) || gotID != id {
+		t.Errorf("got (%s, %s), want (%s, %s)", gotCreatedAt, gotID, createdAt, id)
+	}
+}
+
+func TestDecodeCursorRejectsMalformed(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	for name, cursor := range map[string]string{
+		"not base64":   "%%%",
+		"no separator": encode("2024-05-01T10:30:00Z"),
+		"bad time":     encode("yesterday|" + uuid.New().String()),
+		"empty id":     encode("2024-05-01T10:30:00Z|"),
+		"id not uuid":  encode("2024-05-01T10:30:00Z|1 OR 1=1"),
+	} {
+		if _, _, err := DecodeCursor(cursor); err != ErrInvalidCursor {
+			t.Errorf("%s: got %v, want ErrInvalidCursor", name, err)
+		}
+	}
+}
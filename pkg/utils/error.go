@@ -51,6 +51,32 @@ const (
 	CodeDatabaseError       = "DATABASE_ERROR"
 	CodeValidationError     = "VALIDATION_ERROR"
 	CodeInternalError       = "INTERNAL_ERROR"
+	CodeTransactionNotFound = "TRANSACTION_NOT_FOUND"
+	CodeIdempotencyConflict = "IDEMPOTENCY_KEY_CONFLICT"
+	CodeHoldNotFound        = "HOLD_NOT_FOUND"
+	CodeHoldNotActive       = "HOLD_NOT_ACTIVE"
+
+	CodeTransactionNotReversible = "TRANSACTION_NOT_REVERSIBLE"
+	CodeReversalExceedsOriginal  = "REVERSAL_EXCEEDS_ORIGINAL"
+	CodeBalanceTypeUnavailable   = "BALANCE_TYPE_SOURCE_UNAVAILABLE"
+
+	CodeBalanceTypeNotFound        = "BALANCE_TYPE_NOT_FOUND"
+	CodeBalanceTypeExists          = "BALANCE_TYPE_ALREADY_EXISTS"
+	CodeBalanceTypeReadOnly        = "BALANCE_TYPE_READ_ONLY"
+	CodeBalanceTypeNotTransferable = "BALANCE_TYPE_NOT_TRANSFERABLE"
+
+	CodeWalletNotActive         = "WALLET_NOT_ACTIVE"
+	CodeWalletNotEmpty          = "WALLET_NOT_EMPTY"
+	CodeInvalidStatusTransition = "INVALID_STATUS_TRANSITION"
+	CodeBatchNotFound           = "BATCH_NOT_FOUND"
+	CodeShuttingDown            = "SERVICE_SHUTTING_DOWN"
+
+	CodeUnauthorized      = "UNAUTHORIZED"
+	CodeAPIClientNotFound = "API_CLIENT_NOT_FOUND"
+	CodeAPIClientExists   = "API_CLIENT_ALREADY_EXISTS"
+
+	CodeWebhookNotFound         = "WEBHOOK_NOT_FOUND"
+	CodeWebhookDeliveryNotFound = "WEBHOOK_DELIVERY_NOT_FOUND"
 )
 
 // IsWalletError checks if an error is a WalletError
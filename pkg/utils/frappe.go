@@ -1,12 +1,13 @@
 package utils
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"net/http"
-	"os"
 	"strings"
+	"time"
 )
 
 type BalanceTypeResponse struct {
@@ -16,77 +17,80 @@ type BalanceTypeResponse struct {
 	} `json:"data"`
 }
 
-// ValidateBalanceTypeFromFrappe checks balance type against Frappe
-func ValidateBalanceTypeFromFrappe(balanceType string) error {
-	baseURL := os.Getenv("FRAPPE_URL")       
-	apiKey := os.Getenv("FRAPPE_API_KEY")
-	apiSecret := os.Getenv("FRAPPE_API_SECRET")
+// FrappeClient talks to the Frappe REST API using token authentication
+type FrappeClient struct {
+	baseURL    string
+	apiKey     string
+	apiSecret  string
+	httpClient *http.Client
+}
 
-	url := fmt.Sprintf("%s/api/resource/Balance%%20Type?fields=[\"name\",\"type_name\"]", baseURL)
+// NewFrappeClient creates a new Frappe client. timeout bounds every request.
+func NewFrappeClient(baseURL, apiKey, apiSecret string, timeout time.Duration) *FrappeClient {
+	return &FrappeClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		apiSecret:  apiSecret,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
 
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("Authorization", "token "+apiKey+":"+apiSecret)
+// FetchBalanceTypes fetches the names of all balance types from the
+// "Balance Type" doctype
+func (c *FrappeClient) FetchBalanceTypes(ctx context.Context) ([]string, error) {
+	url := fmt.Sprintf("%s/api/resource/Balance%%20Type?fields=[\"name\",\"type_name\"]&limit_page_length=0", c.baseURL)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return NewWalletError(CodeInternalError, "failed to connect to frappe", err.Error())
+		return nil, NewWalletError(CodeInternalError, "failed to build frappe request", err.Error())
+	}
+	req.Header.Set("Authorization", "token "+c.apiKey+":"+c.apiSecret)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, NewWalletError(CodeInternalError, "failed to connect to frappe", err.Error())
 	}
 	defer resp.Body.Close()
 
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, NewWalletError(CodeInternalError, "failed to read frappe response", err.Error())
+	}
 	if resp.StatusCode != http.StatusOK {
-		return NewWalletError(CodeInternalError, "failed to fetch balance types from frappe", fmt.Sprintf("status code: %d", resp.StatusCode))
+		return nil, NewWalletError(CodeInternalError, "failed to fetch balance types from frappe", fmt.Sprintf("status code: %d", resp.StatusCode))
 	}
 
-	body, _ := ioutil.ReadAll(resp.Body)
-	fmt.Println("FRAPPE RESPONSE:", string(body)) // DEBUG
-
 	var result BalanceTypeResponse
 	if err := json.Unmarshal(body, &result); err != nil {
-		return NewWalletError(CodeInternalError, "invalid response from frappe", string(body))
+		return nil, NewWalletError(CodeInternalError, "invalid response from frappe", string(body))
 	}
 
-	
+	types := []string{}
 	for _, b := range result.Data {
-		if strings.TrimSpace(b.TypeName) == strings.TrimSpace(balanceType) {
-			return nil
+		t := strings.TrimSpace(b.TypeName)
+		if t != "" {
+			types = append(types, t)
 		}
 	}
-
-	return NewWalletError(CodeInvalidBalanceType, "invalid balance type", "type not found in frappe")
+	return types, nil
 }
 
-// GetAllBalanceTypesFromFrappe fetch list of all balance types
-func GetAllBalanceTypesFromFrappe() ([]string, error) {
-	baseURL := os.Getenv("FRAPPE_URL")
-	apiKey := os.Getenv("FRAPPE_API_KEY")
-	apiSecret := os.Getenv("FRAPPE_API_SECRET")
-
-	url := fmt.Sprintf("%s/api/resource/Balance%%20Type?fields=[\"name\",\"type_name\"]", baseURL)
-
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("Authorization", "token "+apiKey+":"+apiSecret)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// Ping checks that Frappe is reachable through its ping method
+func (c *FrappeClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/method/ping", nil)
 	if err != nil {
-		return nil, err
+		return NewWalletError(CodeInternalError, "failed to build frappe request", err.Error())
 	}
-	defer resp.Body.Close()
 
-	var result struct {
-		Data []map[string]string `json:"data"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return NewWalletError(CodeInternalError, "failed to connect to frappe", err.Error())
 	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
 
-	types := []string{}
-	for _, item := range result.Data {
-		t := strings.TrimSpace(item["type_name"])
-		if t != "" {
-			types = append(types, t)
-		}
+	if resp.StatusCode != http.StatusOK {
+		return NewWalletError(CodeInternalError, "frappe ping failed", fmt.Sprintf("status code: %d", resp.StatusCode))
 	}
-	return types, nil
-}
\ No newline at end of file
+	return nil
+}
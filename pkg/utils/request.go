@@ -1,7 +1,174 @@
 package utils
 
+// CreateWalletRequest represents the request to create a wallet
+type CreateWalletRequest struct {
+	// WalletUserID is the wallet's unique handle; a UUID is generated when empty
+	WalletUserID string `json:"wallet_user_id"`
+
+	// OwnerID is the user the wallet belongs to; defaults to WalletUserID
+	OwnerID string `json:"owner_id" validate:"max=255"`
+	Kind    string `json:"kind" validate:"omitempty,oneof=customer seller platform escrow"`
+
+	InitialBalances map[string]string      `json:"initial_balances"`
+	Metadata        map[string]interface{} `json:"metadata"`
+}
+
 // UpdateBalanceRequest represents the request to update wallet balance
 type UpdateBalanceRequest struct {
-	BalanceType string `json:"type" validate:"required"`
-	Amount      string  `json:"amount" validate:"required,numeric"`
-}
\ No newline at end of file
+	BalanceType string                 `json:"type" validate:"required"`
+	Amount      string                 `json:"amount" validate:"required,numeric"`
+	Reference   string                 `json:"reference" validate:"max=255"`
+	Metadata    map[string]interface{} `json:"metadata"`
+
+	// IdempotencyKey may also be sent as the Idempotency-Key header
+	IdempotencyKey string `json:"idempotency_key" validate:"max=255"`
+}
+
+// ListTransactionsRequest represents the query parameters for listing wallet transactions
+type ListTransactionsRequest struct {
+	BalanceType string `query:"type"`
+	Direction   string `query:"direction" validate:"omitempty,oneof=credit debit"`
+	From        string `query:"from"`
+	To          string `query:"to"`
+	Reference   string `query:"reference"`
+	Cursor      string `query:"cursor"`
+	Limit       int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// TransferRequest represents the request to move balance between two wallets
+type TransferRequest struct {
+	FromWalletUserID string                 `json:"from_wallet_user_id" validate:"required"`
+	ToWalletUserID   string                 `json:"to_wallet_user_id" validate:"required"`
+	BalanceType      string                 `json:"type" validate:"required"`
+	Amount           string                 `json:"amount" validate:"required,numeric"`
+	Reference        string                 `json:"reference" validate:"max=255"`
+	Metadata         map[string]interface{} `json:"metadata"`
+
+	// IdempotencyKey may also be sent as the Idempotency-Key header
+	IdempotencyKey string `json:"idempotency_key" validate:"max=255"`
+}
+
+// CreateHoldRequest represents the request to reserve part of a wallet balance
+type CreateHoldRequest struct {
+	BalanceType string                 `json:"type" validate:"required"`
+	Amount      string                 `json:"amount" validate:"required,numeric"`
+	Reference   string                 `json:"reference" validate:"max=255"`
+	Metadata    map[string]interface{} `json:"metadata"`
+
+	// TTLSeconds overrides the default hold lifetime
+	TTLSeconds int `json:"ttl_seconds" validate:"omitempty,min=1,max=2592000"`
+}
+
+// CaptureHoldRequest represents the request to capture a hold. An empty
+// amount captures the full held amount.
+type CaptureHoldRequest struct {
+	Amount string `json:"amount" validate:"omitempty,numeric"`
+}
+
+// ReverseTransactionRequest represents the request to refund a debit. An
+// empty amount refunds everything that has not been refunded yet.
+type ReverseTransactionRequest struct {
+	Amount    string                 `json:"amount" validate:"omitempty,numeric"`
+	Reference string                 `json:"reference" validate:"max=255"`
+	Metadata  map[string]interface{} `json:"metadata"`
+
+	// IdempotencyKey may also be sent as the Idempotency-Key header
+	IdempotencyKey string `json:"idempotency_key" validate:"max=255"`
+}
+
+// UpdateBalanceTypeRequest represents the request to change balance type
+// settings. Omitted fields are left unchanged; an empty limit removes it.
+type UpdateBalanceTypeRequest struct {
+	Scale             *int32  `json:"scale" validate:"omitempty,min=0,max=18"`
+	MinPerTransaction *string `json:"min_per_transaction" validate:"omitempty,max=40"`
+	MaxPerTransaction *string `json:"max_per_transaction" validate:"omitempty,max=40"`
+	Transferable      *bool   `json:"transferable"`
+	Withdrawable      *bool   `json:"withdrawable"`
+}
+
+// CreateBalanceTypeRequest represents the request to create a balance type
+type CreateBalanceTypeRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+	UpdateBalanceTypeRequest
+}
+
+// ChangeWalletStatusRequest represents the request to change a wallet's status
+type ChangeWalletStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=active frozen debit_blocked closed"`
+	Reason string `json:"reason" validate:"required,max=500"`
+
+	// SweepTo receives the remaining balances when a non-empty wallet is closed
+	SweepTo string `json:"sweep_to"`
+}
+
+// BatchItemRequest represents one row of a batch credit
+type BatchItemRequest struct {
+	WalletUserID string `json:"wallet_user_id"`
+	BalanceType  string `json:"type"`
+	Amount       string `json:"amount"`
+}
+
+// CreateBatchRequest represents the request to credit many wallets at once
+type CreateBatchRequest struct {
+	Reference string             `json:"reference" validate:"max=255"`
+	Items     []BatchItemRequest `json:"items" validate:"required,min=1,max=100000"`
+
+	// IdempotencyKey may also be sent as the Idempotency-Key header
+	IdempotencyKey string `json:"idempotency_key" validate:"max=255"`
+}
+
+// ListBatchItemsRequest represents the query parameters for listing batch rows
+type ListBatchItemsRequest struct {
+	Status   string `query:"status" validate:"omitempty,oneof=pending succeeded failed"`
+	AfterRow int    `query:"after_row" validate:"min=0"`
+	Limit    int    `query:"limit" validate:"omitempty,min=1,max=1000"`
+}
+
+// CreateAPIClientRequest represents the request to register an API client
+type CreateAPIClientRequest struct {
+	Name   string   `json:"name" validate:"required,max=255"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=wallet:read wallet:create wallet:credit wallet:debit wallet:admin"`
+}
+
+// UpdateAPIClientRequest represents the request to change an API client.
+// Omitted fields are left unchanged.
+type UpdateAPIClientRequest struct {
+	Name     *string  `json:"name" validate:"omitempty,max=255"`
+	Scopes   []string `json:"scopes" validate:"omitempty,min=1,dive,oneof=wallet:read wallet:create wallet:credit wallet:debit wallet:admin"`
+	Disabled *bool    `json:"disabled"`
+}
+
+// CreateWebhookRequest represents the request to subscribe a URL to events
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" validate:"required,url,max=2048"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,oneof=* wallet.created balance.credited balance.debited"`
+
+	// Secret signs the payloads; one is generated when empty
+	Secret string `json:"secret" validate:"omitempty,min=16,max=128"`
+}
+
+// UpdateWebhookRequest represents the request to change a webhook
+// subscription. Omitted fields are left unchanged.
+type UpdateWebhookRequest struct {
+	URL        *string  `json:"url" validate:"omitempty,url,max=2048"`
+	EventTypes []string `json:"event_types" validate:"omitempty,min=1,dive,oneof=* wallet.created balance.credited balance.debited"`
+	Active     *bool    `json:"active"`
+}
+
+// ListWebhookDeliveriesRequest represents the query parameters for listing deliveries
+type ListWebhookDeliveriesRequest struct {
+	Status string `query:"status" validate:"omitempty,oneof=pending delivered dead"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// FrappeBalanceTypeEvent is the body of a Frappe webhook on the "Balance Type"
+// doctype. Event accepts both short names and Frappe doc events, e.g.
+// "insert" or "after_insert".
+type FrappeBalanceTypeEvent struct {
+	Event    string `json:"event" validate:"required,oneof=insert after_insert update on_update rename after_rename delete on_trash"`
+	Name     string `json:"name"`
+	TypeName string `json:"type_name" validate:"required,max=100"`
+
+	// OldTypeName is the previous type name when a balance type is renamed
+	OldTypeName string `json:"old_type_name" validate:"max=100"`
+}
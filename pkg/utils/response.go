@@ -63,4 +63,19 @@ func InternalServerErrorResponse(c *fiber.Ctx, message string) error {
 // ConflictResponse returns a conflict response
 func ConflictResponse(c *fiber.Ctx, message string) error {
 	return ErrorResponse(c, fiber.StatusConflict, message, nil)
-}
\ No newline at end of file
+}
+
+// ServiceUnavailableResponse returns a service unavailable response
+func ServiceUnavailableResponse(c *fiber.Ctx, message string) error {
+	return ErrorResponse(c, fiber.StatusServiceUnavailable, message, nil)
+}
+
+// AcceptedResponse returns an accepted response for work that continues in the background
+func AcceptedResponse(c *fiber.Ctx, message string, data interface{}) error {
+	return c.Status(fiber.StatusAccepted).JSON(APIResponse{
+		Success:   true,
+		Message:   message,
+		Data:      data,
+		Timestamp: time.Now(),
+	})
+}
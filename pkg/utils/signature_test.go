@@ -0,0 +1,40 @@
+package utils
+
+import "testing"
+
+func TestSignPayload(t *testing.T) {
+	body := []byte(`{"event":"wallet.credited"}`)
+
+	// dihitung terpisah: printf '1700000000.<body>' | openssl dgst -sha256 -hmac whsec_test
+	want := "t=1700000000,v1=82e76295cf99bcb2e55b641aaef640e8a8a494f2d19269737c13e5f4f592e80e"
+	if got := SignPayload("whsec_test", 1700000000, body); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// timestamp ikut ditandatangani, jadi signature lama tidak bisa dipakai ulang
+	if SignPayload("whsec_test", 1700000001, body)[len("t=1700000001,"):] == want[len("t=1700000000,"):] {
+		t.Error("signature does not cover the timestamp")
+	}
+	if SignPayload("other", 1700000000, body) == want {
+		t.Error("signature does not depend on the secret")
+	}
+}
+
+func TestVerifyFrappeSignature(t *testing.T) {
+	body := []byte(`{"name":"Coins"}`)
+	signature := "ZAbbvg5TK4HzBkv3fmrXlFxv9TDB1u961AcHXX4tz/E="
+
+	if !VerifyFrappeSignature("frappe", body, signature) {
+		t.Error("valid signature was rejected")
+	}
+	for name, check := range map[string]bool{
+		"wrong secret": VerifyFrappeSignature("other", body, signature),
+		"changed body": VerifyFrappeSignature("frappe", []byte(`{"name":"Exp"}`), signature),
+		"not base64":   VerifyFrappeSignature("frappe", body, "%%%"),
+		"empty":        VerifyFrappeSignature("frappe", body, ""),
+	} {
+		if check {
+			t.Errorf("%s: signature was accepted", name)
+		}
+	}
+}
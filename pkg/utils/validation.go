@@ -5,10 +5,14 @@ import (
 	"strings"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/shopspring/decimal"
 )
 
 var validate = validator.New()
 
+// maxAmount is the largest amount accepted in a single operation
+var maxAmount = decimal.NewFromInt(1000000000)
+
 // ValidationError represents validation error details
 type ValidationError struct {
 	Field   string `json:"field"`
@@ -64,20 +68,24 @@ func ValidateWalletUserID(walletUserID string) error {
 	return nil
 }
 
-// ValidateAmount validates amount value (float64)
-func ValidateAmount(amount float64) error {
-	if amount <= 0 {
+// ValidateAmount validates a decimal amount against the balance type scale.
+// Amounts with more decimal places than the scale are rejected, not rounded.
+func ValidateAmount(amount decimal.Decimal, scale int32) error {
+	if !amount.IsPositive() {
 		return NewWalletError(CodeInvalidAmount, "amount must be positive", "")
 	}
 
-	if amount > 1000000000 { // 1 billion limit
+	if amount.GreaterThan(maxAmount) { // 1 billion limit
 		return NewWalletError(CodeInvalidAmount, "amount is too large", "maximum 1,000,000,000 allowed")
 	}
 
+	if !amount.Equal(amount.Truncate(scale)) {
+		return NewWalletError(CodeInvalidAmount, "amount has too many decimal places", FormatScale(scale))
+	}
+
 	return nil
 }
 
-
 // ToSnakeCase converts camelCase to snake_case
 func ToSnakeCase(str string) string {
 	var result strings.Builder